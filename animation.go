@@ -0,0 +1,239 @@
+package matcha
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Easing maps the linear progress of an animation, between 0 and 1, to its
+// eased progress. Easings return 0 for 0 and 1 for 1, but may overshoot in
+// between.
+type Easing func(t float64) float64
+
+// Built-in easings.
+var (
+	Linear    Easing = func(t float64) float64 { return t }
+	EaseIn    Easing = func(t float64) float64 { return t * t * t }
+	EaseOut   Easing = func(t float64) float64 { return 1 - math.Pow(1-t, 3) }
+	EaseInOut Easing = func(t float64) float64 {
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		return 1 - math.Pow(-2*t+2, 3)/2
+	}
+	// EaseOutBack overshoots the target slightly before settling.
+	EaseOutBack Easing = func(t float64) float64 {
+		const c1 = 1.70158
+		const c3 = c1 + 1
+		return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+	}
+	// EaseOutBounce bounces against the target a few times before settling.
+	EaseOutBounce Easing = func(t float64) float64 {
+		const n1, d1 = 7.5625, 2.75
+		switch {
+		case t < 1/d1:
+			return n1 * t * t
+		case t < 2/d1:
+			t -= 1.5 / d1
+			return n1*t*t + 0.75
+		case t < 2.5/d1:
+			t -= 2.25 / d1
+			return n1*t*t + 0.9375
+		default:
+			t -= 2.625 / d1
+			return n1*t*t + 0.984375
+		}
+	}
+)
+
+// Lerp interpolates between two numbers.
+func Lerp(from, to, t float64) float64 {
+	return from + (to-from)*t
+}
+
+// LerpInt interpolates between two integers, rounding to the nearest one.
+// It is suited to layout properties such as widths and offsets.
+func LerpInt(from, to int, t float64) int {
+	return int(math.Round(Lerp(float64(from), float64(to), t)))
+}
+
+// LerpColor interpolates between two colors in RGB space. It can be passed
+// to UseTween.
+func LerpColor(from, to lipgloss.Color, t float64) lipgloss.Color {
+	return lerpColor(from, to, t)
+}
+
+// lerpColor is LerpColor for any terminal color, adaptive ones included.
+func lerpColor(from, to lipgloss.TerminalColor, t float64) lipgloss.Color {
+	r1, g1, b1, _ := from.RGBA()
+	r2, g2, b2, _ := to.RGBA()
+	blend := func(a, b uint32) uint8 {
+		return uint8(min(max(Lerp(float64(a), float64(b), t)/257, 0), 255))
+	}
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", blend(r1, r2), blend(g1, g2), blend(b1, b2)))
+}
+
+// AnimationOptions configures UseAnimation.
+type AnimationOptions struct {
+	Duration time.Duration
+	// Easing defaults to Linear.
+	Easing Easing
+	// Loop restarts the animation every time it completes.
+	Loop bool
+	// Alternate makes a looping animation run backwards every other time.
+	Alternate bool
+	// Autoplay starts the animation on the first render.
+	Autoplay bool
+}
+
+// Animation is the current state of an animation returned by UseAnimation.
+type Animation struct {
+	// Progress is the eased progress, 0 before the animation starts and 1
+	// once it completes.
+	Progress float64
+	// Running is true while the animation has not completed.
+	Running bool
+	// Play starts the animation from the beginning.
+	Play func()
+	// Stop freezes the animation at its current progress.
+	Stop func()
+}
+
+// animationState is the local state of UseAnimation.
+type animationState struct {
+	started time.Time
+	stopped float64 // Linear progress at which the animation was stopped.
+	running bool
+}
+
+// UseAnimation drives an animation from 0 to 1 over the given duration.
+//
+// Animations are driven by the build loop's frame clock: while an animation
+// runs, the component is rerendered on every frame with an updated Progress,
+// and once it completes (or is stopped) no further frames are requested on
+// its behalf.
+//
+// Like all slot-based hooks, UseAnimation must be called unconditionally and
+// in the same order on every render.
+func UseAnimation(ctx *Context, options AnimationOptions) Animation {
+	now := frameTime(ctx)
+	state, setState := UseState(ctx, animationState{started: now, running: options.Autoplay})
+
+	easing := options.Easing
+	if easing == nil {
+		easing = Linear
+	}
+
+	linear := state.stopped
+	running := state.running
+	if running {
+		linear, running = animationProgress(now.Sub(state.started), options)
+	}
+	if running {
+		requestFrame(ctx)
+	}
+
+	return Animation{
+		Progress: easing(linear),
+		Running:  running,
+		Play: func() {
+			setState(func(animationState) animationState {
+				return animationState{started: time.Now(), running: true}
+			})
+		},
+		Stop: func() {
+			setState(func(s animationState) animationState {
+				if s.running {
+					s.stopped, _ = animationProgress(time.Since(s.started), options)
+					s.running = false
+				}
+				return s
+			})
+		},
+	}
+}
+
+// animationProgress returns the linear progress of an animation `elapsed`
+// after it started, and whether it is still running.
+func animationProgress(elapsed time.Duration, options AnimationOptions) (float64, bool) {
+	if options.Duration <= 0 {
+		return 1, false
+	}
+	elapsed = max(elapsed, 0)
+	if !options.Loop {
+		if elapsed >= options.Duration {
+			return 1, false
+		}
+		return float64(elapsed) / float64(options.Duration), true
+	}
+
+	cycle := int64(elapsed / options.Duration)
+	progress := float64(elapsed%options.Duration) / float64(options.Duration)
+	if options.Alternate && cycle%2 == 1 {
+		progress = 1 - progress
+	}
+	return progress, true
+}
+
+// TweenOptions configures UseTween.
+type TweenOptions struct {
+	Duration time.Duration
+	// Easing defaults to EaseInOut.
+	Easing Easing
+}
+
+// tweenState is the bookkeeping of UseTween.
+type tweenState[T comparable] struct {
+	from, to T
+	started  time.Time
+}
+
+// UseTween returns a value that follows `target` smoothly: whenever target
+// changes, the returned value moves from wherever it currently is to the new
+// target over the configured duration, using `lerp` to interpolate.
+//
+// Lerp, LerpInt and LerpColor cover numbers, layout properties such as
+// widths and offsets, and colors:
+//
+//	width := UseTween(ctx, Conditional(expanded, 40, 10), LerpInt, TweenOptions{Duration: 200 * time.Millisecond})
+//	style := lipgloss.NewStyle().Width(width)
+//
+//	accent := UseTween(ctx, Conditional(focused, lipgloss.Color("#61AFEF"), lipgloss.Color("#5C6370")), LerpColor, TweenOptions{Duration: 150 * time.Millisecond})
+//
+// The first render returns target as is. Frames are only requested while a
+// tween is in progress.
+//
+// Like all slot-based hooks, UseTween must be called unconditionally and in
+// the same order on every render.
+func UseTween[T comparable](ctx *Context, target T, lerp func(from, to T, t float64) T, options TweenOptions) T {
+	now := frameTime(ctx)
+	tween := useRef(ctx, tweenState[T]{from: target, to: target, started: now})
+
+	easing := options.Easing
+	if easing == nil {
+		easing = EaseInOut
+	}
+
+	progress := func() float64 {
+		if options.Duration <= 0 {
+			return 1
+		}
+		return min(max(float64(now.Sub(tween.started))/float64(options.Duration), 0), 1)
+	}
+
+	if target != tween.to {
+		// Restart from the current value so that retargeting mid-way is smooth.
+		current := lerp(tween.from, tween.to, easing(progress()))
+		*tween = tweenState[T]{from: current, to: target, started: now}
+	}
+
+	t := progress()
+	if t >= 1 {
+		return tween.to
+	}
+	requestFrame(ctx)
+	return lerp(tween.from, tween.to, easing(t))
+}
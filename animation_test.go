@@ -0,0 +1,32 @@
+package matcha
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// TestLerpColor checks the endpoints and midpoint of LerpColor, and that it
+// has the signature UseTween expects.
+func TestLerpColor(t *testing.T) {
+	// Colors are read through the color profile, which is ASCII when the
+	// output isn't a terminal.
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(profile)
+
+	var lerp func(from, to lipgloss.Color, t float64) lipgloss.Color = LerpColor
+	for _, tt := range []struct {
+		t    float64
+		want lipgloss.Color
+	}{
+		{0, "#000000"},
+		{0.5, "#7f7f7f"},
+		{1, "#ffffff"},
+	} {
+		if got := lerp("#000000", "#ffffff", tt.t); got != tt.want {
+			t.Errorf("LerpColor(#000000, #ffffff, %v) = %s, want %s", tt.t, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package matcha
+
+import "context"
+
+// Cmd is a unit of background work, such as a database query or a
+// subprocess. It runs on its own goroutine and must return promptly once
+// `ctx` is cancelled.
+type Cmd[T any] func(ctx context.Context) (T, error)
+
+// AsyncStatus is the state of the work started by UseAsync.
+type AsyncStatus int
+
+const (
+	// AsyncIdle means the work has not started, or was cancelled.
+	AsyncIdle AsyncStatus = iota
+	// AsyncLoading means the work is running.
+	AsyncLoading
+	// AsyncSuccess means the work completed, and Value holds its result.
+	AsyncSuccess
+	// AsyncError means the work failed, and Err holds its error.
+	AsyncError
+)
+
+// Async is the state of the work started by UseAsync.
+type Async[T any] struct {
+	Status AsyncStatus
+	// Value is the result of the last successful run. It is kept while the
+	// work runs again, so that stale data can be shown while reloading.
+	Value T
+	Err   error
+	// Reload runs the work again, cancelling the current run if any.
+	Reload func()
+	// Cancel cancels the current run, if any, and returns to AsyncIdle.
+	Cancel func()
+}
+
+// Loading reports whether the work is running.
+func (a Async[T]) Loading() bool {
+	return a.Status == AsyncLoading
+}
+
+// asyncState is the local state of UseAsync. It is only accessed on the
+// build loop: while rendering and from the callbacks posted to it.
+type asyncState[T any] struct {
+	status  AsyncStatus
+	value   T
+	err     error
+	deps    []any
+	started bool               // Whether the work was started for the current deps.
+	run     int                // Incremented for every run; results of older runs are dropped.
+	cancel  context.CancelFunc // Cancels the current run.
+	mounted bool               // Whether the unmount cleanup is registered.
+}
+
+// stop cancels the current run, if any, and drops its result.
+func (s *asyncState[T]) stop() {
+	s.run++
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	if s.status == AsyncLoading {
+		s.status = AsyncIdle
+	}
+}
+
+// UseAsync runs `cmd` on a goroutine when the component is first rendered
+// and whenever one of `deps` changes, and returns the state of the work:
+//
+//	users := UseAsync(ctx, func(c context.Context) ([]User, error) { return db.Users(c, query) }, query)
+//	if users.Loading() {
+//		return Spinner(SpinnerProps{Label: "Loading…"})
+//	}
+//
+// Dependencies are compared as in UseMemo. Starting a new run cancels the
+// previous one, and the context passed to `cmd` is also cancelled when the
+// component is unmounted or the application quits. Results are delivered on
+// the build loop between two frames, never while the tree is walked, and the
+// component is rendered again with them. Results of cancelled runs are
+// dropped.
+//
+// Like all slot-based hooks, UseAsync must be called unconditionally and in
+// the same order on every render.
+func UseAsync[T any](ctx *Context, cmd Cmd[T], deps ...any) Async[T] {
+	state := useRef(ctx, asyncState[T]{})
+	lifecycle := ctx.managers.lifecycle
+
+	start := func() {
+		state.stop()
+		taskCtx, cancel := lifecycle.context()
+		run := state.run
+		state.status, state.err, state.cancel = AsyncLoading, nil, cancel
+
+		go lifecycle.guard(func() {
+			value, err := cmd(taskCtx)
+			ctx.post(func() {
+				if state.run != run {
+					return
+				}
+				state.stop()
+				if err != nil {
+					state.status, state.err = AsyncError, err
+				} else {
+					state.status, state.value = AsyncSuccess, value
+				}
+			})
+		})
+	}
+
+	if !state.mounted {
+		state.mounted = true
+		lifecycle.onUnmount(componentID(ctx.id), func() {
+			state.stop()
+			// Run again if the component is mounted again.
+			state.mounted, state.started = false, false
+		})
+	}
+	if !state.started || !depsEqual(state.deps, deps) {
+		state.started, state.deps = true, deps
+		start()
+	}
+
+	return Async[T]{
+		Status: state.status,
+		Value:  state.value,
+		Err:    state.err,
+		Reload: func() { ctx.post(start) },
+		Cancel: func() { ctx.post(state.stop) },
+	}
+}
+
+// RunCmd runs `cmd` on a goroutine, typically from an event handler, and
+// calls `done` with its result on the build loop, between two frames, where
+// it can safely update state:
+//
+//	RunCmd(ctx, save(document), func(_ struct{}, err error) {
+//		setStatus(func(string) string { return statusOf(err) })
+//	})
+//
+// The context passed to `cmd` is cancelled when the component is unmounted,
+// when the application quits, or when the returned function is called; in
+// that case `done` is not called.
+func RunCmd[T any](ctx *Context, cmd Cmd[T], done func(value T, err error)) (cancel func()) {
+	lifecycle := ctx.managers.lifecycle
+	taskCtx, cancelTask := lifecycle.context()
+	remove := lifecycle.onUnmount(componentID(ctx.id), cancelTask)
+
+	go lifecycle.guard(func() {
+		value, err := cmd(taskCtx)
+		ctx.post(func() {
+			remove()
+			if taskCtx.Err() != nil {
+				return
+			}
+			cancelTask()
+			if done != nil {
+				done(value, err)
+			}
+		})
+	})
+	return cancelTask
+}
@@ -0,0 +1,127 @@
+package matcha
+
+import "sync"
+
+// batch holds the notifications and frame requests deferred by Batch.
+//
+// Batches are global rather than per goroutine: while a batch runs, updates
+// made from other goroutines are deferred along with it.
+var batch struct {
+	depth      int
+	pending    []func()
+	queued     map[any]struct{} // Atoms with a pending notification.
+	schedulers map[*scheduler]struct{}
+	mu         sync.Mutex
+}
+
+// Batch runs `fn` and defers the notifications of the atoms it updates until
+// it returns, so that updating several atoms in one handler causes a single
+// notification per atom and a single frame:
+//
+//	Batch(func() {
+//		setFirstName(func(string) string { return first })
+//		setLastName(func(string) string { return last })
+//	})
+//
+// Each atom is notified once with its latest value. Computed atoms are only
+// computed again once all the updates are applied, so they never see some
+// of the batch's updates without the others. Batches can be nested; only
+// the outermost one notifies.
+func Batch(fn func()) {
+	batch.mu.Lock()
+	batch.depth++
+	batch.mu.Unlock()
+
+	defer endBatch()
+	fn()
+}
+
+// endBatch ends the current batch. The outermost batch runs the pending
+// notifications, including those queued by the notifications themselves,
+// then wakes the build loops up. If a notification panics, the batch still
+// ends: the notifications left are dropped, and the build loops woken up.
+func endBatch() {
+	ended := false
+	defer func() {
+		if ended {
+			return
+		}
+		batch.mu.Lock()
+		batch.depth = 0
+		batch.pending, batch.queued = nil, nil
+		schedulers := batch.schedulers
+		batch.schedulers = nil
+		batch.mu.Unlock()
+
+		for s := range schedulers {
+			s.wakeUp()
+		}
+	}()
+
+	for {
+		batch.mu.Lock()
+		if batch.depth > 1 {
+			batch.depth--
+			batch.mu.Unlock()
+			ended = true
+			return
+		}
+		if len(batch.pending) == 0 {
+			batch.depth = 0
+			schedulers := batch.schedulers
+			batch.schedulers = nil
+			batch.mu.Unlock()
+			ended = true
+
+			for s := range schedulers {
+				s.wakeUp()
+			}
+			return
+		}
+		pending := batch.pending
+		batch.pending, batch.queued = nil, nil
+		batch.mu.Unlock()
+
+		for _, notify := range pending {
+			notify()
+		}
+	}
+}
+
+// deferNotify queues the notification of an atom if a batch is running, and
+// reports whether it did. An atom is only queued once per batch.
+//
+// Thread-safe.
+func deferNotify(atom any, notify func()) bool {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	if batch.depth == 0 {
+		return false
+	}
+	if _, ok := batch.queued[atom]; ok {
+		return true
+	}
+	if batch.queued == nil {
+		batch.queued = make(map[any]struct{})
+	}
+	batch.queued[atom] = struct{}{}
+	batch.pending = append(batch.pending, notify)
+	return true
+}
+
+// deferWake postpones waking a build loop up if a batch is running, and
+// reports whether it did.
+//
+// Thread-safe.
+func deferWake(s *scheduler) bool {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	if batch.depth == 0 {
+		return false
+	}
+	if batch.schedulers == nil {
+		batch.schedulers = make(map[*scheduler]struct{})
+	}
+	batch.schedulers[s] = struct{}{}
+	return true
+}
@@ -0,0 +1,32 @@
+package matcha
+
+import "testing"
+
+// TestBatchPanickingWatcher checks that a watcher panicking while a batch
+// notifies ends the batch.
+func TestBatchPanickingWatcher(t *testing.T) {
+	atom := NewAtom(0)
+	panicking := true
+	atom.watch(func() {
+		if panicking {
+			panicking = false
+			panic("watcher")
+		}
+	})
+	func() {
+		defer func() { recover() }()
+		Batch(func() { atom.update(func(v int) int { return v + 1 }) })
+	}()
+
+	var notified bool
+	atom.watch(func() { notified = true })
+	atom.update(func(v int) int { return v + 1 })
+	if !notified {
+		t.Error("update after a panicking batch was not notified")
+	}
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	if batch.depth != 0 {
+		t.Errorf("batch depth %d after it panicked, want 0", batch.depth)
+	}
+}
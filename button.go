@@ -0,0 +1,77 @@
+package matcha
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// ButtonProps configures a Button.
+type ButtonProps struct {
+	// ID is the focusable ID registered through UseFocus.
+	ID      string
+	Label   string
+	OnPress func()
+	// Disabled buttons ignore input and are drawn with DisabledStyle.
+	Disabled bool
+
+	Style         lipgloss.Style
+	FocusedStyle  lipgloss.Style
+	PressedStyle  lipgloss.Style
+	DisabledStyle lipgloss.Style
+}
+
+type button struct {
+	props ButtonProps
+}
+
+// Button renders a pressable label.
+//
+// A button is pressed with Enter or Space while focused, or with a click,
+// which also focuses it. Pressing draws the button with PressedStyle for a
+// single frame before it returns to its focused style.
+func Button(props ButtonProps) Component {
+	return &button{props: props}
+}
+
+func (b *button) Render(ctx *Context) Component {
+	isFocused, setFocus, _ := UseFocus(ctx, b.props.ID)
+	pressed, setPressed := UseState(ctx, false)
+	clock := ctx.managers.clock
+
+	press := func() {
+		setPressed(func(bool) bool { return true })
+		clock.afterNextFrame(func() {
+			setPressed(func(bool) bool { return false })
+		})
+		if b.props.OnPress != nil {
+			b.props.OnPress()
+		}
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if b.props.Disabled {
+			return false
+		}
+		if isPrimaryClick(event) {
+			setFocus(b.props.ID)
+			press()
+			return true
+		}
+		if key, ok := event.(*tcell.EventKey); ok && isActivationKey(key) {
+			press()
+			return true
+		}
+		return false
+	})
+
+	style := b.props.Style
+	switch {
+	case b.props.Disabled:
+		style = b.props.DisabledStyle
+	case pressed:
+		style = b.props.PressedStyle
+	case isFocused:
+		style = b.props.FocusedStyle
+	}
+	return Text(b.props.Label, style)
+}
@@ -0,0 +1,157 @@
+package matcha
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// ChannelPolicy decides what UseChannel keeps of the values it receives.
+type ChannelPolicy int
+
+const (
+	// ChannelLatest keeps only the latest value, for streams of states such
+	// as metrics where older values are obsolete.
+	ChannelLatest ChannelPolicy = iota
+	// ChannelBuffer keeps the latest values in order, up to a size, for
+	// streams of events such as log lines.
+	ChannelBuffer
+)
+
+// ChannelOptions configures UseChannel.
+type ChannelOptions struct {
+	Policy ChannelPolicy
+	// Size is the number of values kept by ChannelBuffer; the oldest values
+	// are dropped first. Defaults to 1000.
+	Size int
+}
+
+// ChannelValues is what UseChannel received so far.
+type ChannelValues[T any] struct {
+	// Latest is the last value received, valid if Received is true.
+	Latest   T
+	Received bool
+	// Values holds the values kept by ChannelBuffer, oldest first. It is
+	// empty with ChannelLatest.
+	Values []T
+	// Closed is true once the channel is closed.
+	Closed bool
+}
+
+// channelState is the local state of UseChannel. The values are written by
+// the reading goroutine and read while rendering, so they are guarded by a
+// mutex; the other fields are only accessed on the build loop.
+type channelState[T any] struct {
+	values ChannelValues[T] // Everything but Values, kept in ring.
+	ring   []T              // Values kept by ChannelBuffer; once full, the oldest is at head.
+	head   int
+	mu     sync.Mutex
+
+	ch      <-chan T
+	cancel  context.CancelFunc // Stops the reading goroutine.
+	mounted bool               // Whether the unmount cleanup is registered.
+}
+
+// stop stops reading, if the channel is being read.
+func (s *channelState[T]) stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// UseChannel reads `ch` on a goroutine and returns what it received so far,
+// rendering the component again as values arrive:
+//
+//	logs := UseChannel(ctx, tail, ChannelOptions{Policy: ChannelBuffer, Size: 500})
+//	for _, line := range logs.Values {
+//		...
+//	}
+//
+// Rerenders are coalesced, so a fast channel causes at most one frame per
+// frame interval, with all the values received in between. Reading stops
+// when the component is unmounted, when the application quits, or when the
+// channel is closed, and starts over, with nothing received, when a
+// different channel is passed.
+//
+// Like all slot-based hooks, UseChannel must be called unconditionally and
+// in the same order on every render.
+func UseChannel[T any](ctx *Context, ch <-chan T, options ChannelOptions) ChannelValues[T] {
+	state := useRef(ctx, &channelState[T]{})
+	s := *state
+	lifecycle := ctx.managers.lifecycle
+	size := options.Size
+	if size <= 0 {
+		size = 1000
+	}
+
+	if !s.mounted {
+		s.mounted = true
+		lifecycle.onUnmount(componentID(ctx.id), func() {
+			s.stop()
+			// Read again if the component is mounted again.
+			s.mounted, s.ch = false, nil
+		})
+	}
+	if s.ch != ch {
+		s.stop()
+		s.ch = ch
+		s.mu.Lock()
+		s.values, s.ring, s.head = ChannelValues[T]{}, nil, 0
+		s.mu.Unlock()
+
+		if ch != nil {
+			readCtx, cancel := lifecycle.context()
+			s.cancel = cancel
+			go s.read(ctx, readCtx, ch, options.Policy, size)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := s.values
+	values.Values = append(slices.Clone(s.ring[s.head:]), s.ring[:s.head]...)
+	return values
+}
+
+// read receives values from `ch` until it is closed or `readCtx` is
+// cancelled, and asks for the component to be rendered again after each.
+func (s *channelState[T]) read(ctx *Context, readCtx context.Context, ch <-chan T, policy ChannelPolicy, size int) {
+	for {
+		var value T
+		var ok bool
+		select {
+		case value, ok = <-ch:
+		case <-readCtx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		if readCtx.Err() != nil {
+			// Another channel replaced this one while receiving.
+			s.mu.Unlock()
+			return
+		}
+		if !ok {
+			s.values.Closed = true
+		} else {
+			s.values.Latest, s.values.Received = value, true
+			if policy == ChannelBuffer {
+				// Once the buffer is full, the newest value replaces the
+				// oldest, which is what keeps a fast channel cheap to read.
+				if len(s.ring) < size {
+					s.ring = append(s.ring, value)
+				} else {
+					s.ring[s.head] = value
+					s.head = (s.head + 1) % size
+				}
+			}
+		}
+		s.mu.Unlock()
+
+		ctx.RequestRender()
+		if !ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package matcha
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type channelReader struct {
+	ch     <-chan int
+	values *ChannelValues[int]
+}
+
+func (c *channelReader) Render(ctx *Context) Component {
+	*c.values = UseChannel(ctx, c.ch, ChannelOptions{Policy: ChannelBuffer, Size: 3})
+	return Text("reader", lipgloss.NewStyle())
+}
+
+// TestUseChannelBuffer checks that a full buffer keeps the latest values,
+// oldest first.
+func TestUseChannelBuffer(t *testing.T) {
+	for _, sent := range []int{2, 3, 5, 7} {
+		ch := make(chan int, sent)
+		var values ChannelValues[int]
+		app := newTestApp(t, &channelReader{ch: ch, values: &values})
+		app.scheduler.invalidate()
+		drawFrame(app)
+		for i := 1; i <= sent; i++ {
+			ch <- i
+		}
+		close(ch)
+
+		deadline := time.Now().Add(2 * time.Second)
+		for !values.Closed && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+			drawFrame(app)
+		}
+		var want []int
+		for i := max(sent-2, 1); i <= sent; i++ {
+			want = append(want, i)
+		}
+		if !values.Closed || !slices.Equal(values.Values, want) {
+			t.Errorf("sent %d values: kept %v (closed %t), want %v", sent, values.Values, values.Closed, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package matcha
+
+import (
+	"sync"
+	"time"
+)
+
+// frameCallback is a callback waiting for a given frame to be drawn.
+type frameCallback struct {
+	frame uint64
+	fn    func()
+}
+
+// clock is the framework's tick source. It counts the frames drawn by the
+// build loop and stamps each frame with a single time shared by every
+// component.
+//
+// Animated components call requestFrame on every render instead of running
+// their own timers: the build loop keeps drawing frames at its own pace for
+// as long as some component keeps asking, rendering only the components that
+// asked, and goes idle as soon as none does.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type clock struct {
+	frame   uint64 // Number of frames started so far.
+	now     time.Time
+	source  func() time.Time // Time source, replaced by tests stepping frames.
+	pending []frameCallback
+	mu      sync.Mutex
+}
+
+// newClock creates and returns a new clock at frame zero.
+func newClock() *clock {
+	return &clock{now: time.Now(), source: time.Now}
+}
+
+// begin marks the start of a new frame, before the tree is walked.
+//
+// Thread-safe.
+func (c *clock) begin() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frame++
+	c.now = c.source()
+}
+
+// afterNextFrame schedules `fn` to run once the next frame to start has been
+// drawn. A frame already in progress does not count, as it may have been
+// walked before the caller's state changed.
+//
+// Thread-safe.
+func (c *clock) afterNextFrame(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, frameCallback{frame: c.frame + 1, fn: fn})
+}
+
+// end marks the end of the current frame and runs the callbacks that were
+// waiting for it. Callbacks run on their own goroutine, as they typically
+// request another frame from the loop calling end, guarded by `lifecycle` so
+// that a panic restores the terminal before ending the application.
+//
+// Thread-safe.
+func (c *clock) end(lifecycle *lifecycleManager) {
+	c.mu.Lock()
+	var ready []frameCallback
+	waiting := c.pending[:0]
+	for _, callback := range c.pending {
+		if callback.frame <= c.frame {
+			ready = append(ready, callback)
+		} else {
+			waiting = append(waiting, callback)
+		}
+	}
+	c.pending = waiting
+	c.mu.Unlock()
+
+	for _, callback := range ready {
+		go lifecycle.guard(callback.fn)
+	}
+}
+
+// frameTime returns the time of the frame being rendered. Every component
+// rendered in the same frame sees the same time.
+func frameTime(ctx *Context) time.Time {
+	clock := ctx.managers.clock
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	return clock.now
+}
+
+// requestFrame asks the build loop for another frame rendering the
+// component again once the current one has been drawn. Components call it on
+// every render for as long as they are animating, and stop calling it to let
+// the loop go idle.
+func requestFrame(ctx *Context) {
+	ctx.scheduler.invalidate(componentID(ctx.id))
+}
+
+// requestFrameAt asks the build loop for a frame rendering the component
+// again once `at` has come. Components whose output only changes at known
+// times, such as spinners, call it instead of requestFrame so that the loop
+// stays idle in between.
+func requestFrameAt(ctx *Context, at time.Time) {
+	ctx.scheduler.invalidateAt(componentID(ctx.id), at)
+}
+
+// useFrameTime returns the time of the frame being rendered and asks the
+// build loop for another frame.
+func useFrameTime(ctx *Context) time.Time {
+	requestFrame(ctx)
+	return frameTime(ctx)
+}
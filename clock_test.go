@@ -0,0 +1,26 @@
+package matcha
+
+import (
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TestFrameCallbackPanic checks that a panic in a callback waiting for a
+// frame is reported to the application rather than ending the process.
+func TestFrameCallbackPanic(t *testing.T) {
+	app := newTestApp(t, Text("text", lipgloss.NewStyle()))
+	app.managers.clock.afterNextFrame(func() { panic("callback") })
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	select {
+	case err := <-app.managers.lifecycle.crashed:
+		if err.Value != "callback" {
+			t.Errorf("reported %v, want the callback's panic", err.Value)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("panic of a frame callback not reported")
+	}
+}
@@ -0,0 +1,298 @@
+package matcha
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Command is an action listed in the command palette.
+type Command struct {
+	// ID identifies the command. Registering a command with an ID that is
+	// already in use replaces the previous one.
+	ID    string
+	Title string
+	// Run is called from the event dispatch goroutine, after the palette
+	// has closed.
+	Run func()
+}
+
+// registeredCommand is a command together with the component that registered it.
+type registeredCommand struct {
+	command Command
+	owner   componentID
+}
+
+// commandManager keeps the commands registered through UseCommand and the
+// order in which they were last run.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type commandManager struct {
+	enabled  bool
+	open     bool
+	commands map[string]registeredCommand
+	used     map[string]int // Command ID to the value of `runs` when it last ran.
+	runs     int
+	mu       sync.Mutex
+}
+
+// newCommandManager creates and returns a new, empty commandManager.
+func newCommandManager() *commandManager {
+	return &commandManager{
+		commands: make(map[string]registeredCommand),
+		used:     make(map[string]int),
+	}
+}
+
+// commandMatch is a command matching the palette's query.
+type commandMatch struct {
+	command Command
+	score   int
+	ranges  [][2]int
+}
+
+// Recently run commands get a bonus added to their match score, so that
+// they come first unless another command matches much better. The last
+// command run gets recencyBonus, which shrinks by recencyDecay for every
+// command run since.
+const (
+	recencyBonus = 16
+	recencyDecay = 2
+)
+
+// search returns the commands matching `query`, best first, scoring each by
+// how well it matches and how recently it ran. Commands with the same score
+// are ranked by how recently they ran, then by title.
+//
+// Thread-safe.
+func (c *commandManager) search(query string) []commandMatch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matches := make([]commandMatch, 0, len(c.commands))
+	for _, registered := range c.commands {
+		score, ranges, ok := fuzzyMatch(query, registered.command.Title)
+		if !ok {
+			continue
+		}
+		if used, ok := c.used[registered.command.ID]; ok {
+			score += max(recencyBonus-(c.runs-used)*recencyDecay, 0)
+		}
+		matches = append(matches, commandMatch{command: registered.command, score: score, ranges: ranges})
+	}
+
+	slices.SortFunc(matches, func(a, b commandMatch) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(c.used[b.command.ID], c.used[a.command.ID]),
+			cmp.Compare(a.command.Title, b.command.Title),
+		)
+	})
+	return matches
+}
+
+// prune unregisters the commands of the components that are not in use, so
+// that commands never outlive the component that registered them.
+//
+// Thread-safe.
+func (c *commandManager) prune(inUse func(id componentID) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, registered := range c.commands {
+		if !inUse(registered.owner) {
+			delete(c.commands, id)
+		}
+	}
+}
+
+// markUsed records that the command just ran.
+//
+// Thread-safe.
+func (c *commandManager) markUsed(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runs++
+	c.used[id] = c.runs
+}
+
+// UseCommand registers a command for the command palette on behalf of the
+// component associated with this Context.
+//
+// Commands are keyed by their ID, so registering the same command on every
+// render simply refreshes it. Commands are unregistered when the component
+// is unmounted. The palette itself is opt-in; see
+// App.EnableCommandPalette.
+// Thread-safe.
+func UseCommand(ctx *Context, command Command) {
+	manager := ctx.managers.command
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+
+	manager.commands[command.ID] = registeredCommand{command: command, owner: componentID(ctx.id)}
+}
+
+// EnableCommandPalette turns on the command palette, opened with Ctrl+P.
+//
+// The palette lists every command registered through UseCommand, narrows
+// them down with fuzzy matching as the user types, and runs the selected one
+// on Enter. Recently run commands are listed first, unless another command
+// matches the query much better. Must be called before Render.
+func (a *App) EnableCommandPalette() {
+	a.managers.command.enabled = true
+}
+
+// interceptPalette opens the command palette if the event is its shortcut.
+// It reports whether the event was consumed.
+func interceptPalette(app *App, event tcell.Event) bool {
+	key, ok := event.(*tcell.EventKey)
+	manager := app.managers.command
+	if !ok || !manager.enabled || key.Key() != tcell.KeyCtrlP {
+		return false
+	}
+
+	manager.mu.Lock()
+	if manager.open {
+		// Let the palette close itself.
+		manager.mu.Unlock()
+		return false
+	}
+	manager.open = true
+	manager.mu.Unlock()
+
+	palette := &commandPalette{}
+	palette.id = app.managers.overlay.push(palette, overlayOptions{place: placeTopCenter, modal: true, dim: true})
+	app.RequestRender()
+	return true
+}
+
+// maxPaletteResults is the number of matches listed by the palette.
+const maxPaletteResults = 10
+
+// paletteState is the local state of the command palette.
+type paletteState struct {
+	query    textValue
+	selected int
+}
+
+// commandPalette is the overlay listing the registered commands.
+type commandPalette struct {
+	id string
+}
+
+func (p *commandPalette) Render(ctx *Context) Component {
+	manager := ctx.managers.command
+	state, setState, getState := useState(ctx, paletteState{})
+
+	// visible returns the matches listed for a state and the index of the
+	// selected one.
+	visible := func(s paletteState) ([]commandMatch, int) {
+		matches := manager.search(s.query.String())
+		matches = matches[:min(len(matches), maxPaletteResults)]
+		return matches, min(s.selected, max(len(matches)-1, 0))
+	}
+	matches, selected := visible(state)
+
+	closePalette := func() {
+		if !ctx.managers.overlay.remove(p.id) {
+			return
+		}
+		manager.mu.Lock()
+		manager.open = false
+		manager.mu.Unlock()
+		ctx.RequestRender()
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok {
+			return false
+		}
+		// Several keys may be handled before the next frame, so each one
+		// works from the latest state rather than the rendered one.
+		switch key.Key() {
+		case tcell.KeyEscape, tcell.KeyCtrlP:
+			closePalette()
+		case tcell.KeyUp:
+			setState(func(s paletteState) paletteState {
+				_, selected := visible(s)
+				s.selected = max(selected-1, 0)
+				return s
+			})
+		case tcell.KeyDown:
+			setState(func(s paletteState) paletteState {
+				matches, selected := visible(s)
+				s.selected = min(selected+1, max(len(matches)-1, 0))
+				return s
+			})
+		case tcell.KeyEnter:
+			matches, selected := visible(getState())
+			if len(matches) == 0 {
+				return true
+			}
+			command := matches[selected].command
+			closePalette()
+			manager.markUsed(command.ID)
+			if command.Run != nil {
+				command.Run()
+			}
+		default:
+			if !isEditKey(key) {
+				return false
+			}
+			setState(func(s paletteState) paletteState {
+				s.query, _ = s.query.edit(key)
+				s.selected = 0
+				return s
+			})
+		}
+		return true
+	})
+
+	accent := lipgloss.Color("#5DA9E9")
+	children := []Component{
+		Row([]Component{
+			Text("> ", lipgloss.NewStyle().Foreground(accent)),
+			renderTextValue(state.query, "Type a command", true, lipgloss.NewStyle()),
+		}, lipgloss.NewStyle().MarginBottom(1)),
+	}
+	if len(matches) == 0 {
+		children = append(children, Text("No matching commands", lipgloss.NewStyle().Faint(true)))
+	}
+	for i, match := range matches {
+		style := lipgloss.NewStyle().Width(48)
+		if i == selected {
+			style = style.Reverse(true)
+		}
+		children = append(children, highlightMatches(match.command.Title, match.ranges, style,
+			inlineStyle(style).Foreground(accent).Bold(true)))
+	}
+
+	return Column(children, lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accent).
+		Padding(0, 1))
+}
+
+// highlightMatches draws `title` with the given rune ranges in the
+// highlight style and the rest in the frame's inline style.
+func highlightMatches(title string, ranges [][2]int, frame, highlight lipgloss.Style) Component {
+	runes := []rune(title)
+	plain := inlineStyle(frame)
+
+	segments := make([]Component, 0, 2*len(ranges)+1)
+	start := 0
+	for _, r := range ranges {
+		if r[0] > start {
+			segments = append(segments, Text(string(runes[start:r[0]]), plain))
+		}
+		segments = append(segments, Text(string(runes[r[0]:r[1]]), highlight))
+		start = r[1]
+	}
+	if start < len(runes) {
+		segments = append(segments, Text(string(runes[start:]), plain))
+	}
+	return Row(segments, frame)
+}
@@ -0,0 +1,93 @@
+package matcha
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+type commands struct {
+	ran *string
+}
+
+func (c *commands) Render(ctx *Context) Component {
+	for _, title := range []string{"Save file", "Save all", "Search"} {
+		UseCommand(ctx, Command{ID: title, Title: title, Run: func() { *c.ran = title }})
+	}
+	return Text("commands", lipgloss.NewStyle())
+}
+
+// TestPaletteKeysBeforeFrame types a query and moves the selection faster
+// than the palette renders.
+func TestPaletteKeysBeforeFrame(t *testing.T) {
+	var ran string
+	app := newTestApp(t, &commands{ran: &ran})
+	app.EnableCommandPalette()
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	interceptPalette(app, tcell.NewEventKey(tcell.KeyCtrlP, 0, tcell.ModCtrl))
+	drawFrame(app)
+	press(t, app, "overlay/1", tcell.KeyRune, 's')
+	press(t, app, "overlay/1", tcell.KeyRune, 'e')
+	press(t, app, "overlay/1", tcell.KeyEnter, 0)
+	if ran != "Search" {
+		t.Errorf("query: ran %q, want %q", ran, "Search")
+	}
+
+	// "Search" ran last, so it comes first, then the others by title.
+	interceptPalette(app, tcell.NewEventKey(tcell.KeyCtrlP, 0, tcell.ModCtrl))
+	drawFrame(app)
+	press(t, app, "overlay/2", tcell.KeyDown, 0)
+	press(t, app, "overlay/2", tcell.KeyDown, 0)
+	press(t, app, "overlay/2", tcell.KeyDown, 0)
+	press(t, app, "overlay/2", tcell.KeyEnter, 0)
+	if ran != "Save file" {
+		t.Errorf("selection: ran %q, want %q", ran, "Save file")
+	}
+}
+
+func TestCommandsUnregisteredOnUnmount(t *testing.T) {
+	var ran string
+	show := true
+	app := newTestApp(t, &shown{show: &show, child: &commands{ran: &ran}})
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if got := len(app.managers.command.search("")); got != 3 {
+		t.Fatalf("%d commands registered, want 3", got)
+	}
+
+	show = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if got := len(app.managers.command.search("")); got != 0 {
+		t.Errorf("%d commands left after unmount, want 0", got)
+	}
+}
+
+// TestPaletteRecency checks that a recently run command outranks a better
+// match, until enough other commands ran since.
+func TestPaletteRecency(t *testing.T) {
+	m := newCommandManager()
+	for _, title := range []string{"Open file", "Show diff"} {
+		m.commands[title] = registeredCommand{command: Command{ID: title, Title: title}}
+	}
+	strong, _, _ := fuzzyMatch("of", "Open file")
+	weak, _, _ := fuzzyMatch("of", "Show diff")
+	if weak >= strong {
+		t.Fatalf("%q scores %d and %q %d, want the first lower", "Show diff", weak, "Open file", strong)
+	}
+
+	m.markUsed("Show diff")
+	if matches := m.search("of"); matches[0].command.ID != "Show diff" {
+		t.Errorf("first match %q, want the command just run", matches[0].command.ID)
+	}
+
+	for range recencyBonus / recencyDecay {
+		m.markUsed("other")
+	}
+	if matches := m.search("of"); matches[0].command.ID != "Open file" {
+		t.Errorf("first match %q once many commands ran since, want the better match", matches[0].command.ID)
+	}
+}
@@ -1,9 +1,13 @@
 package matcha
 
 type Context struct {
-	id       string
-	channels *channels
-	managers *managers
+	id        string
+	channels  *channels
+	managers  *managers
+	scheduler *scheduler
+	node      *node         // Node being built for the component; its parents are the component's ancestors.
+	dirty     *invalidation // Components rendered again in the current frame.
+	hooks     int           // Number of slot-based hooks (e.g. UseState) called so far during this render.
 }
 
 func (c *Context) Quit() {
@@ -0,0 +1,295 @@
+package matcha
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// DialogButton is an action shown at the bottom of a Dialog.
+type DialogButton struct {
+	Label string
+	// OnPress is called after the dialog closes, with the content of the
+	// dialog's input (empty if it has none).
+	OnPress func(value string)
+}
+
+// DialogInput adds a single-line text input to a Dialog.
+type DialogInput struct {
+	Initial     string
+	Placeholder string
+}
+
+// DialogProps configures a Dialog.
+type DialogProps struct {
+	Title   string
+	Body    Component
+	Input   *DialogInput
+	Buttons []DialogButton
+	// OnDismiss is called after the dialog closes through Escape.
+	OnDismiss func()
+
+	Style             lipgloss.Style
+	TitleStyle        lipgloss.Style
+	InputStyle        lipgloss.Style
+	ButtonStyle       lipgloss.Style
+	ActiveButtonStyle lipgloss.Style
+}
+
+// dialogState is the local state of a Dialog.
+type dialogState struct {
+	button int
+	input  textValue
+}
+
+type dialog struct {
+	props DialogProps
+	close func() bool
+}
+
+// Dialog renders a framed box with a title, a body, an optional text input
+// and a row of buttons. Use OpenDialog to show it as a modal overlay.
+//
+// Keyboard: Tab/Shift+Tab and Left/Right (when there is no input) cycle
+// through the buttons, Enter presses the active one and Escape dismisses the
+// dialog. Buttons can also be clicked.
+func Dialog(props DialogProps) Component {
+	return &dialog{props: props}
+}
+
+// OpenDialog shows a dialog centered on top of the application, dimming
+// everything beneath it. Keyboard and mouse input is trapped inside the
+// dialog until it closes, either through one of its buttons, Escape, or the
+// returned close function.
+//
+// OpenDialog does not block and can be called from event handlers.
+func OpenDialog(ctx *Context, props DialogProps) (close func()) {
+	manager := ctx.managers.overlay
+	d := &dialog{props: props}
+	id := manager.push(d, overlayOptions{place: placeCenter, modal: true, dim: true})
+	d.close = func() bool {
+		if !manager.remove(id) {
+			return false
+		}
+		ctx.RequestRender()
+		return true
+	}
+
+	ctx.RequestRender()
+	return func() { d.close() }
+}
+
+func (d *dialog) Render(ctx *Context) Component {
+	initial := dialogState{}
+	if d.props.Input != nil {
+		initial.input = newTextValue(d.props.Input.Initial)
+	}
+	state, setState, getState := useState(ctx, initial)
+
+	// Several keys may be handled before the next frame, so the handlers
+	// work from the latest state rather than the rendered one.
+	press := func(index int) {
+		input := getState().input
+		if d.close != nil && !d.close() {
+			return
+		}
+		if index >= 0 && index < len(d.props.Buttons) && d.props.Buttons[index].OnPress != nil {
+			d.props.Buttons[index].OnPress(input.String())
+		}
+	}
+
+	dismiss := func() {
+		if d.close != nil && !d.close() {
+			return
+		}
+		if d.props.OnDismiss != nil {
+			d.props.OnDismiss()
+		}
+	}
+
+	selectButton := func(offset int) {
+		count := len(d.props.Buttons)
+		if count == 0 {
+			return
+		}
+		setState(func(s dialogState) dialogState {
+			s.button = (s.button + offset + count) % count
+			return s
+		})
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok {
+			return false
+		}
+		switch key.Key() {
+		case tcell.KeyEscape:
+			dismiss()
+		case tcell.KeyEnter:
+			press(getState().button)
+		case tcell.KeyTab:
+			selectButton(1)
+		case tcell.KeyBacktab:
+			selectButton(-1)
+		default:
+			if d.props.Input != nil {
+				if !isEditKey(key) {
+					return false
+				}
+				setState(func(s dialogState) dialogState {
+					s.input, _ = s.input.edit(key)
+					return s
+				})
+				return true
+			}
+			switch key.Key() {
+			case tcell.KeyRight:
+				selectButton(1)
+			case tcell.KeyLeft:
+				selectButton(-1)
+			default:
+				return false
+			}
+		}
+		return true
+	})
+
+	var children []Component
+	if d.props.Title != "" {
+		children = append(children, Text(d.props.Title, d.props.TitleStyle))
+	}
+	if d.props.Body != nil {
+		children = append(children, d.props.Body)
+	}
+	if d.props.Input != nil {
+		children = append(children, renderTextValue(state.input, d.props.Input.Placeholder, true, d.props.InputStyle))
+	}
+	if len(d.props.Buttons) > 0 {
+		buttons := make([]Component, 0, len(d.props.Buttons))
+		for i, button := range d.props.Buttons {
+			style := d.props.ButtonStyle
+			if i == state.button {
+				style = d.props.ActiveButtonStyle
+			}
+			buttons = append(buttons, &dialogButton{label: button.Label, style: style, onPress: func() { press(i) }})
+		}
+		children = append(children, Row(buttons, lipgloss.NewStyle().MarginTop(1)))
+	}
+
+	return Column(children, d.props.Style)
+}
+
+// dialogButton is a clickable button of a Dialog.
+type dialogButton struct {
+	label   string
+	style   lipgloss.Style
+	onPress func()
+}
+
+func (b *dialogButton) Render(ctx *Context) Component {
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if !isPrimaryClick(event) {
+			return false
+		}
+		b.onPress()
+		return true
+	})
+	return Text(b.label, b.style)
+}
+
+// defaultDialogProps returns the props shared by Alert, Confirm and Prompt.
+func defaultDialogProps(title, message string) DialogProps {
+	props := DialogProps{
+		Title:             title,
+		Style:             lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1),
+		TitleStyle:        lipgloss.NewStyle().Bold(true).MarginBottom(1),
+		InputStyle:        lipgloss.NewStyle().MarginTop(1),
+		ButtonStyle:       lipgloss.NewStyle().Padding(0, 1),
+		ActiveButtonStyle: lipgloss.NewStyle().Padding(0, 1).Reverse(true),
+	}
+	if message != "" {
+		props.Body = Text(message, lipgloss.NewStyle())
+	}
+	return props
+}
+
+// Alert shows a modal dialog with a message and a single OK button.
+//
+// `onClose`, which may be nil, is called once the dialog is closed, either
+// through its button or Escape. The returned channel is closed at the same
+// time, so callers outside of event handlers can wait on it instead.
+func Alert(ctx *Context, title, message string, onClose func()) <-chan struct{} {
+	done := make(chan struct{})
+	report := func() {
+		close(done)
+		if onClose != nil {
+			onClose()
+		}
+	}
+
+	props := defaultDialogProps(title, message)
+	props.Buttons = []DialogButton{{Label: "OK", OnPress: func(string) { report() }}}
+	props.OnDismiss = report
+	OpenDialog(ctx, props)
+	return done
+}
+
+// Confirm shows a modal dialog asking the user to confirm or cancel.
+//
+// `onResult`, which may be nil, is called with true if the user confirmed
+// and false if they cancelled or pressed Escape. The result is also sent on
+// the returned channel, which has room for it so the dialog never blocks.
+func Confirm(ctx *Context, title, message string, onResult func(ok bool)) <-chan bool {
+	result := make(chan bool, 1)
+	report := func(ok bool) {
+		result <- ok
+		close(result)
+		if onResult != nil {
+			onResult(ok)
+		}
+	}
+
+	props := defaultDialogProps(title, message)
+	props.Buttons = []DialogButton{
+		{Label: "OK", OnPress: func(string) { report(true) }},
+		{Label: "Cancel", OnPress: func(string) { report(false) }},
+	}
+	props.OnDismiss = func() { report(false) }
+	OpenDialog(ctx, props)
+	return result
+}
+
+// PromptResult is the outcome of a Prompt dialog. OK is false if the user
+// cancelled, in which case Value is empty.
+type PromptResult struct {
+	Value string
+	OK    bool
+}
+
+// Prompt shows a modal dialog asking the user to enter a line of text,
+// pre-filled with `initial`.
+//
+// `onResult`, which may be nil, is called with the entered text once the
+// user confirms, or with ok set to false if they cancelled or pressed Escape.
+// The result is also sent on the returned channel, which has room for it so
+// the dialog never blocks.
+func Prompt(ctx *Context, title, message, initial string, onResult func(value string, ok bool)) <-chan PromptResult {
+	result := make(chan PromptResult, 1)
+	report := func(value string, ok bool) {
+		result <- PromptResult{Value: value, OK: ok}
+		close(result)
+		if onResult != nil {
+			onResult(value, ok)
+		}
+	}
+
+	props := defaultDialogProps(title, message)
+	props.Input = &DialogInput{Initial: initial}
+	props.Buttons = []DialogButton{
+		{Label: "OK", OnPress: func(value string) { report(value, true) }},
+		{Label: "Cancel", OnPress: func(string) { report("", false) }},
+	}
+	props.OnDismiss = func() { report("", false) }
+	OpenDialog(ctx, props)
+	return result
+}
@@ -0,0 +1,27 @@
+package matcha
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TestPromptKeysBeforeFrame types into a prompt faster than it renders.
+func TestPromptKeysBeforeFrame(t *testing.T) {
+	app := newTestApp(t, &handled{})
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	result := Prompt(app.newContext("root", nil, nil), "Name", "", "", nil)
+	drawFrame(app)
+
+	for _, r := range "hello" {
+		press(t, app, "overlay/1", tcell.KeyRune, r)
+	}
+	press(t, app, "overlay/1", tcell.KeyBackspace2, 0)
+	press(t, app, "overlay/1", tcell.KeyEnter, 0)
+
+	if got := <-result; got != (PromptResult{Value: "hell", OK: true}) {
+		t.Errorf("got %+v, want hell", got)
+	}
+}
@@ -2,8 +2,8 @@ package matcha
 
 import (
 	"fmt"
-	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -26,41 +26,72 @@ type node struct {
 	box       *box
 }
 
+// build draws a frame whenever one is requested, paced by the app's
+// scheduler.
 func build(app *App) {
-	buffer := 0
-	ticker := time.NewTicker(time.Second / 24)
+	scheduler := app.scheduler
 	for {
+		var due bool
 		select {
-		case <-app.channels.render:
-			buffer++
-			if buffer >= 10 {
-				tree := walk(app, app.root, "root", nil)
-				app.channels.tree <- tree
-				box := pack(tree, 0, 0)
-				render(app.screen, box, app)
-				buffer = 0
-			}
-
-		case <-ticker.C:
-			if buffer != 0 {
-				tree := walk(app, app.root, "root", nil)
-				app.channels.tree <- tree
-				box := pack(tree, 0, 0)
-				render(app.screen, box, app)
-				buffer = 0
-			}
+		case <-scheduler.wake:
+			due = scheduler.due()
+		case <-scheduler.timer.C:
+			due = scheduler.fired()
 		case <-app.channels.quit:
 			return
 		}
+		if due {
+			scheduler.draw(func() { frame(app) })
+		}
 	}
 }
 
-func walk(app *App, component Component, id string, parent *node) *node {
+// frame walks and lays out the scene, hands it over to the event dispatcher
+// and draws it. Only the components invalidated since the last frame are
+// rendered again, and the components that disappeared from the scene are
+// unmounted.
+//
+// The scene is handed over only once every box is at its final position:
+// the dispatcher hit tests the boxes concurrently, so a published scene is
+// never written to again.
+func frame(app *App) {
+	app.managers.clock.begin()
+	s := compose(app, app.scheduler.take())
+	width, height := app.screen.Size()
+	canvas := paint(s, width, height)
+	app.scene.Store(s)
+	app.managers.prune(app.managers.lifecycle.commit(s))
+	render(app.screen, canvas)
+	app.managers.clock.end(app.managers.lifecycle)
+}
+
+// walk renders the component with the given ID and its descendants into a
+// tree of nodes.
+//
+// `prev` is the node the component had in the previous frame, if any, and
+// `fresh` tells whether the component is a new instance because its parent
+// was rendered again. When the component is not fresh and no component in
+// its subtree was invalidated, the subtree is reused as is, boxes included.
+// Otherwise invalidated and fresh components are rendered again, which makes
+// their children fresh, while the custom components above them are not:
+// their last output is walked again to reach the invalidated ones.
+//
+// A fresh component implementing Memoizable that equals the previous one is
+// treated as if it was not fresh.
+func walk(app *App, component Component, id string, parent *node, prev *node, fresh bool, dirty *invalidation) *node {
+	if m, ok := component.(Memoizable); ok && fresh && prev != nil && m.Equal(prev.component) {
+		fresh = false
+	}
+	if prev != nil && !fresh && !dirty.reaches(id) {
+		return prev.clone(parent)
+	}
+	rerender := fresh || prev == nil || dirty.marks(id)
+
 	node := &node{
 		id:     id,
 		parent: parent,
 	}
-	ctx := app.newContext(id)
+	ctx := app.newContext(id, node, dirty)
 	switch c := component.(type) {
 
 	case *text:
@@ -68,8 +99,8 @@ func walk(app *App, component Component, id string, parent *node) *node {
 
 	case *column:
 		for i, child := range c.children {
-			childID := fmt.Sprintf("%s/%d", id, i)
-			childNode := walk(app, child, childID, node)
+			cid := childID(id, i, child)
+			childNode := walk(app, child, cid, node, prev.child(i, cid), fresh, dirty)
 			node.children = append(node.children, childNode)
 		}
 		node.component = c.Render(ctx)
@@ -77,26 +108,129 @@ func walk(app *App, component Component, id string, parent *node) *node {
 	case *row:
 		node.component = c
 		for i, child := range c.children {
-			childID := fmt.Sprintf("%s/%d", id, i)
-			childNode := walk(app, child, childID, node)
+			cid := childID(id, i, child)
+			childNode := walk(app, child, cid, node, prev.child(i, cid), fresh, dirty)
 			node.children = append(node.children, childNode)
 		}
 		node.component = c.Render(ctx)
 	default:
-		rendered := c.Render(ctx)
-		node.component = rendered
-		childNode := walk(app, rendered, id+"/0", parent)
-		node.children = append(node.children, childNode)
+		var rendered Component
+		if !rerender && len(prev.children) == 1 {
+			rendered = prev.children[0].component
+		} else {
+			rendered = c.Render(ctx)
+			rerender = true
+		}
+		node.component = c
+		walkChild := func(rendered Component) {
+			cid := childID(id, 0, rendered)
+			childNode := walk(app, rendered, cid, node, prev.child(0, cid), rerender, dirty)
+			node.children = append(node.children, childNode)
+		}
+		if b, ok := c.(*errorBoundary); ok {
+			b.guard(boundaryStateOf(app.managers.state, componentID(id)), walkChild, rendered)
+		} else {
+			walkChild(rendered)
+		}
 	}
 
 	return node
 }
 
+// child returns the child of a previous frame's node with the given ID, or
+// nil if there is none. The child at `index` is tried first, as children
+// rarely move between frames.
+func (n *node) child(index int, id string) *node {
+	if n == nil {
+		return nil
+	}
+	if index < len(n.children) && n.children[index].id == id {
+		return n.children[index]
+	}
+	for _, child := range n.children {
+		if child.id == id {
+			return child
+		}
+	}
+	return nil
+}
+
+// clone copies the subtree for reuse under a new parent. Nodes are copied,
+// as the event dispatcher may still be reading the previous frame's tree,
+// but components and boxes are shared.
+func (n *node) clone(parent *node) *node {
+	c := &node{
+		id:        n.id,
+		component: n.component,
+		parent:    parent,
+		box:       n.box,
+		children:  make([]*node, 0, len(n.children)),
+	}
+	for _, child := range n.children {
+		c.children = append(c.children, child.clone(c))
+	}
+	return c
+}
+
+// move shifts every box of the subtree by (dx, dy). Boxes are copied before
+// being moved, as they may be shared with a previous frame's tree; their
+// grids are not, since they do not depend on the position.
+func (n *node) move(dx, dy int) {
+	if n.box != nil {
+		b := *n.box
+		b.x += dx
+		b.y += dy
+		n.box = &b
+	}
+	for _, child := range n.children {
+		child.move(dx, dy)
+	}
+}
+
+// childID derives the ID of the child at the given index. Children that
+// implement HasKey with a non-empty key are identified by that key instead of
+// their position, so their state survives siblings being inserted, removed or
+// reordered between renders.
+func childID(parent string, index int, child Component) string {
+	if keyed, ok := child.(HasKey); ok && keyed.Key() != "" {
+		return fmt.Sprintf("%s/#%s", parent, keyed.Key())
+	}
+	return fmt.Sprintf("%s/%d", parent, index)
+}
+
+// pack lays out the tree with its top-left corner at (x, y) and returns the
+// box of the root. Every node's box is stored on the node so that events can
+// later be hit tested against it.
+//
+// Text is rendered directly, columns stack their children vertically, rows
+// place them side by side, and custom components take the box of whatever
+// they rendered. Subtrees reused from the previous frame keep their boxes
+// and are only moved. Boxes shared with the previous frame are never
+// written to, as the event dispatcher may still be reading them.
 func pack(tree *node, x, y int) *box {
+	if tree.box != nil {
+		if dx, dy := x-tree.box.x, y-tree.box.y; dx != 0 || dy != 0 {
+			tree.move(dx, dy)
+		}
+		return tree.box
+	}
+
 	var b *box
 	switch c := tree.component.(type) {
 	case *text:
 		b = toBox(c.content, c.style)
+		b.x, b.y = x, y
+	case *column:
+		b = packChildren(tree.children, c.style, x, y, true)
+	case *row:
+		b = packChildren(tree.children, c.style, x, y, false)
+	default:
+		// The child is placed at (x, y), and its box may be shared.
+		if len(tree.children) > 0 {
+			b = pack(tree.children[0], x, y)
+		} else {
+			b = &box{x: x, y: y}
+		}
 	}
 
 	tree.box = b
@@ -104,22 +238,76 @@ func pack(tree *node, x, y int) *box {
 	return b
 }
 
+// packChildren lays out children one after another, vertically when
+// `vertical` is true and horizontally otherwise, and draws them inside the
+// frame (margin, border, padding and background) described by `style`.
+//
+// The frame is produced by rendering an empty block of the required size
+// through toBox, so borders and colors are handled exactly as for text.
+func packChildren(children []*node, style lipgloss.Style, x, y int, vertical bool) *box {
+	mt, _, _, ml := style.GetMargin()
+	pt, pr, pb, pl := style.GetPadding()
+	offsetX := x + ml + style.GetBorderLeftSize() + pl
+	offsetY := y + mt + style.GetBorderTopSize() + pt
+
+	boxes := make([]*box, 0, len(children))
+	width, height := 0, 0
+	for _, child := range children {
+		var b *box
+		if vertical {
+			b = pack(child, offsetX, offsetY+height)
+			height += b.height
+			width = max(width, b.width)
+		} else {
+			b = pack(child, offsetX+width, offsetY)
+			width += b.width
+			height = max(height, b.height)
+		}
+		boxes = append(boxes, b)
+	}
+
+	if len(children) == 0 && style.GetWidth() == 0 && style.GetHeight() == 0 {
+		if w, h := style.GetFrameSize(); w == 0 && h == 0 {
+			// Empty containers without a frame take no space.
+			return &box{x: x, y: y}
+		}
+	}
+
+	frame := style.
+		Width(max(style.GetWidth(), width+pl+pr)).
+		Height(max(style.GetHeight(), height+pt+pb))
+	b := toBox("", frame)
+	b.x, b.y = x, y
+	for _, child := range boxes {
+		b.copyInto(child)
+	}
+
+	return b
+}
+
+// copyInto draws the child's grid onto b at the child's position. Cells
+// falling outside of b are clipped.
 func (b *box) copyInto(child *box) {
 	for row := 0; row < child.height; row++ {
+		y := row + child.y - b.y
+		if y < 0 || y >= b.height {
+			continue
+		}
 		for col := 0; col < child.width; col++ {
-			ch := child.grid[row][col]
-			b.grid[row+child.y-b.y][col+child.x-b.x] = ch
+			x := col + child.x - b.x
+			if x < 0 || x >= b.width {
+				continue
+			}
+			b.grid[y][x] = child.grid[row][col]
 		}
 	}
 }
 
-func render(screen tcell.Screen, box *box, app *App) {
+func render(screen tcell.Screen, box *box) {
 	for y, row := range box.grid {
 		for x, column := range row {
 			screen.SetContent(box.x+x, box.y+y, column.ch, column.comb, column.style)
 		}
 	}
 	screen.Show()
-	time.Sleep(time.Second * 2)
-	close(app.channels.quit)
 }
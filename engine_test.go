@@ -0,0 +1,93 @@
+package matcha
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// shifting renders a list whose first line grows on every render, so that
+// the boxes of the lines below it move from one frame to the next.
+type shifting struct {
+	renders *int
+}
+
+func (s *shifting) Render(ctx *Context) Component {
+	*s.renders++
+	lines := []Component{&counter{n: *s.renders}}
+	for i := range 20 {
+		lines = append(lines, Text("line "+strconv.Itoa(i), lipgloss.NewStyle()))
+	}
+	return Row([]Component{Text("x", lipgloss.NewStyle()), Column(lines, lipgloss.NewStyle())}, lipgloss.NewStyle())
+}
+
+type counter struct {
+	n int
+}
+
+func (c *counter) Render(ctx *Context) Component {
+	return Text(strconv.Itoa(c.n), lipgloss.NewStyle().Height(1+c.n%3))
+}
+
+func newTestApp(t *testing.T, root Component) *App {
+	t.Helper()
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(80, 24)
+	t.Cleanup(screen.Fini)
+
+	app := NewApp(root)
+	app.screen = screen
+	return app
+}
+
+// drawFrame draws a frame, rendering the components invalidated since the
+// last one.
+func drawFrame(app *App) {
+	app.scheduler.draw(func() { frame(app) })
+}
+
+// TestFrameHandoff draws frames while scenes are hit tested the way the
+// event dispatcher does. Run with -race: a published scene must never be
+// written to.
+func TestFrameHandoff(t *testing.T) {
+	var renders int
+	app := newTestApp(t, &shifting{renders: &renders})
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if s := app.scene.Load(); s != nil {
+				for y := range 24 {
+					if n := findDeepestNodeAtPosition(s.root, 2, y); n != nil && n.box == nil {
+						t.Error("hit node without a box")
+					}
+				}
+			}
+		}
+	}()
+
+	for range 100 {
+		app.scheduler.invalidate()
+		drawFrame(app)
+	}
+	close(done)
+	wg.Wait()
+
+	if got := app.FrameStats().Frames; got != 100 {
+		t.Errorf("drew %d frames, want 100", got)
+	}
+}
@@ -0,0 +1,225 @@
+package matcha
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// PanicError is a panic recovered by an ErrorBoundary, or by the application
+// before it reports it.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+// newPanicError wraps a recovered value. It must be called from the deferred
+// function that recovered it, so that the stack trace includes the panic.
+func newPanicError(value any) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint(e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// report prints the panic with its original stack trace to stderr, once the
+// terminal is restored: the trace of the panic raised again only shows
+// Render.
+func (e *PanicError) report() {
+	fmt.Fprintf(os.Stderr, "panic: %v\n\n%s\n", e.Value, e.Stack)
+}
+
+// ErrorBoundaryProps configures an ErrorBoundary.
+type ErrorBoundaryProps struct {
+	Child Component
+	// Fallback renders what is shown instead of Child after it panicked.
+	// Calling `reset` renders Child again, from a clean state: Child is
+	// unmounted while the fallback is shown, which drops the local state of
+	// its subtree. Defaults to the error and its stack trace with a retry
+	// button.
+	Fallback func(err *PanicError, reset func()) Component
+	// OnError is called with every panic recovered, for logging.
+	OnError func(err *PanicError)
+}
+
+type errorBoundary struct {
+	props ErrorBoundaryProps
+}
+
+// boundaryState is the local state of an ErrorBoundary, kept in its first
+// hook slot. The error is set while walking and from event handlers, so it
+// is guarded by a mutex.
+type boundaryState struct {
+	err   *PanicError
+	reset func()
+	mu    sync.Mutex
+}
+
+// boundaryStateOf returns the state of the boundary with the given ID, or
+// nil if it has none, as once it is unmounted.
+//
+// Thread-safe.
+func boundaryStateOf(states *stateManager, id componentID) *boundaryState {
+	states.mu.Lock()
+	defer states.mu.Unlock()
+	if slots := states.slots[id]; len(slots) > 0 {
+		if ref, ok := slots[0].(**boundaryState); ok {
+			return *ref
+		}
+	}
+	return nil
+}
+
+// failure returns the panic the boundary shows, if any, and the function
+// resetting it.
+func (s *boundaryState) failure() (*PanicError, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err, s.reset
+}
+
+// ErrorBoundary renders `props.Child`, or `props.Fallback` once a component
+// below it panicked, so that a bug in one panel doesn't take down the whole
+// application:
+//
+//	ErrorBoundary(ErrorBoundaryProps{Child: &preview{path: path}})
+//
+// Panics are recovered while rendering the subtree and in the event
+// handlers of its components, and go to the nearest boundary above the
+// component that panicked. A panic in a fallback goes to the next boundary
+// up. Other panics, including those of commands run by UseAsync and RunCmd,
+// end the application: Render restores the terminal, prints the original
+// stack trace to stderr and panics again with the original value.
+func ErrorBoundary(props ErrorBoundaryProps) Component {
+	return &errorBoundary{props: props}
+}
+
+func (b *errorBoundary) Render(ctx *Context) Component {
+	state := *useRef(ctx, &boundaryState{})
+	state.mu.Lock()
+	if state.reset == nil {
+		state.reset = func() {
+			state.mu.Lock()
+			state.err = nil
+			state.mu.Unlock()
+			ctx.RequestRender()
+		}
+	}
+	state.mu.Unlock()
+
+	// The fallback is built without holding the lock, as it may call reset.
+	if err, _ := state.failure(); err != nil {
+		return b.fallback(state)
+	}
+	return b.props.Child
+}
+
+// fallback returns what the boundary shows for the panic it recorded. It is
+// keyed, so that its state is never mixed up with the child's.
+func (b *errorBoundary) fallback(state *boundaryState) Component {
+	err, reset := state.failure()
+	var child Component
+	if b.props.Fallback != nil {
+		child = b.props.Fallback(err, reset)
+	} else {
+		child = &errorFallback{err: err, reset: reset}
+	}
+	return &boundaryFallback{child: child}
+}
+
+// fail records a panic of the subtree.
+func (b *errorBoundary) fail(state *boundaryState, err *PanicError) {
+	state.mu.Lock()
+	state.err = err
+	state.mu.Unlock()
+	if b.props.OnError != nil {
+		b.props.OnError(err)
+	}
+}
+
+// guard walks what the boundary rendered with `walkChild` and, if that
+// panics, records the panic and walks the fallback instead.
+func (b *errorBoundary) guard(state *boundaryState, walkChild func(Component), rendered Component) {
+	if _, ok := rendered.(*boundaryFallback); ok {
+		// Panics of the fallback go further up.
+		walkChild(rendered)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			b.fail(state, newPanicError(r))
+			walkChild(b.fallback(state))
+		}
+	}()
+	walkChild(rendered)
+}
+
+// recoverHandler calls an event handler, routing a panic to the nearest
+// ErrorBoundary above `n`, the handler's node, whose state is looked up in
+// `states`. It returns the node of that
+// boundary, to be rendered again, or the panic if there is no boundary.
+func recoverHandler(states *stateManager, n *node, handler func() bool) (handled bool, boundary *node, err *PanicError) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		err = newPanicError(r)
+		for boundary = n; boundary != nil; boundary = boundary.parent {
+			b, ok := boundary.component.(*errorBoundary)
+			if !ok {
+				continue
+			}
+			state := boundaryStateOf(states, componentID(boundary.id))
+			if state == nil {
+				continue
+			}
+			if failed, _ := state.failure(); failed == nil {
+				b.fail(state, err)
+				handled, err = true, nil
+				return
+			}
+		}
+	}()
+	return handler(), nil, nil
+}
+
+type boundaryFallback struct {
+	child Component
+}
+
+func (f *boundaryFallback) Render(ctx *Context) Component {
+	return f.child
+}
+
+func (f *boundaryFallback) Key() string {
+	return "error"
+}
+
+// errorFallback is the default fallback of ErrorBoundary.
+type errorFallback struct {
+	err   *PanicError
+	reset func()
+}
+
+func (f *errorFallback) Render(ctx *Context) Component {
+	stack := strings.TrimRight(string(f.err.Stack), "\n")
+	return Column([]Component{
+		Text("panic: "+f.err.Error(), lipgloss.NewStyle().Foreground(lipgloss.Color("#E06C75")).Bold(true)),
+		Text(stack, lipgloss.NewStyle().Faint(true).MarginTop(1).MarginBottom(1)),
+		Button(ButtonProps{ID: ctx.id + "/retry", Label: "Retry", OnPress: f.reset}),
+	}, lipgloss.NewStyle().Padding(0, 1))
+}
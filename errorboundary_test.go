@@ -0,0 +1,192 @@
+package matcha
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+type flaky struct {
+	fail  *bool
+	count *int
+	set   *func(func(int) int)
+}
+
+func (c *flaky) Render(ctx *Context) Component {
+	count, setCount := UseState(ctx, 0)
+	*c.count, *c.set = count, setCount
+	if *c.fail {
+		panic("flaky")
+	}
+	return Text("flaky", lipgloss.NewStyle())
+}
+
+// TestErrorBoundaryReset checks that resetting a boundary renders its child
+// again from a clean state.
+func TestErrorBoundaryReset(t *testing.T) {
+	var fail bool
+	var count int
+	var setCount func(func(int) int)
+	var reset func()
+	app := newTestApp(t, ErrorBoundary(ErrorBoundaryProps{
+		Child: &flaky{fail: &fail, count: &count, set: &setCount},
+		Fallback: func(err *PanicError, r func()) Component {
+			reset = r
+			return Text("failed", lipgloss.NewStyle())
+		},
+	}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+	setCount(func(int) int { return 5 })
+	drawFrame(app)
+	if count != 5 {
+		t.Fatalf("count = %d, want 5", count)
+	}
+
+	fail = true
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if reset == nil {
+		t.Fatal("fallback not rendered")
+	}
+
+	fail = false
+	reset()
+	drawFrame(app)
+	if count != 0 {
+		t.Errorf("count = %d after reset, want 0", count)
+	}
+}
+
+type panickingUpdater struct{}
+
+func (c *panickingUpdater) Render(ctx *Context) Component {
+	_, setCount := UseState(ctx, 0)
+	UseEvent(ctx, func(tcell.Event) bool {
+		setCount(func(int) int { panic("updater") })
+		return true
+	})
+	return Text("counter", lipgloss.NewStyle())
+}
+
+// TestErrorBoundaryPanickingUpdater checks that a state updater panicking in
+// an event handler below a boundary leaves later frames able to render.
+func TestErrorBoundaryPanickingUpdater(t *testing.T) {
+	var failed bool
+	app := newTestApp(t, ErrorBoundary(ErrorBoundaryProps{
+		Child: &panickingUpdater{},
+		Fallback: func(err *PanicError, reset func()) Component {
+			failed = true
+			return Text("failed", lipgloss.NewStyle())
+		},
+	}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	n := findNodeByID(app.scene.Load().root, "root/0")
+	handler := app.managers.event.handlers["root/0"]
+	_, boundary, err := recoverHandler(app.managers.state, n, func() bool {
+		return handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	})
+	if err != nil || boundary == nil {
+		t.Fatalf("panic not routed to the boundary: %v", err)
+	}
+	app.scheduler.invalidate(componentID(boundary.id))
+
+	done := make(chan struct{})
+	go func() {
+		drawFrame(app)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("frame after the panic did not complete")
+	}
+	if !failed {
+		t.Error("fallback not rendered")
+	}
+}
+
+// rendered returns the content of the texts rendered below `n`.
+func rendered(n *node) []string {
+	var texts []string
+	if t, ok := n.component.(*text); ok {
+		texts = append(texts, t.content)
+	}
+	for _, child := range n.children {
+		texts = append(texts, rendered(child)...)
+	}
+	return texts
+}
+
+// TestErrorBoundaryFallbackResets checks that a fallback may reset the
+// boundary while it is built, as an automatic retry does.
+func TestErrorBoundaryFallbackResets(t *testing.T) {
+	fail := true
+	var count int
+	var setCount func(func(int) int)
+	var calls int
+	app := newTestApp(t, ErrorBoundary(ErrorBoundaryProps{
+		Child: &flaky{fail: &fail, count: &count, set: &setCount},
+		Fallback: func(err *PanicError, reset func()) Component {
+			// Retry once the boundary renders the fallback itself.
+			if calls++; calls == 2 {
+				fail = false
+				reset()
+			}
+			return Text("retrying", lipgloss.NewStyle())
+		},
+	}))
+	app.scheduler.invalidate()
+
+	done := make(chan struct{})
+	go func() {
+		drawFrame(app)
+		app.scheduler.invalidate()
+		drawFrame(app)
+		drawFrame(app)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("frame with a fallback calling reset did not complete")
+	}
+	if texts := rendered(app.scene.Load().root); !slices.Equal(texts, []string{"flaky"}) {
+		t.Errorf("rendered %q after retrying, want the child", texts)
+	}
+}
+
+// TestErrorBoundarySharedValue checks that two boundaries made from the same
+// value keep separate states.
+func TestErrorBoundarySharedValue(t *testing.T) {
+	boundary := ErrorBoundary(ErrorBoundaryProps{
+		Child: &panickingUpdater{},
+		Fallback: func(err *PanicError, reset func()) Component {
+			return Text("failed", lipgloss.NewStyle())
+		},
+	})
+	app := newTestApp(t, Column([]Component{boundary, boundary}, lipgloss.NewStyle()))
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	n := app.scene.Load().root.children[0].children[0]
+	handler := app.managers.event.handlers[n.id]
+	if _, _, err := recoverHandler(app.managers.state, n, func() bool {
+		return handler(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	app.scheduler.invalidate()
+	drawFrame(app)
+	root := app.scene.Load().root
+	for i, want := range []string{"failed", "counter"} {
+		if texts := rendered(root.children[i]); !slices.Equal(texts, []string{want}) {
+			t.Errorf("boundary %d rendered %q, want %q", i, texts, want)
+		}
+	}
+}
@@ -22,30 +22,37 @@ func newEventManager() *eventManager {
 	}
 }
 
+// prune drops the handlers of the components that are not in use.
+//
+// Thread-safe.
+func (e *eventManager) prune(inUse func(id componentID) bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for id := range e.handlers {
+		if !inUse(componentID(id)) {
+			delete(e.handlers, id)
+		}
+	}
+}
+
 func dispatch(app *App) {
-	var tree *node
 	for {
-		select {
-		case t := <-app.channels.tree:
-			tree = t
-		default:
-		}
 		select {
 		case event := <-app.channels.event:
-			if tree == nil {
+			if _, ok := event.(*tcell.EventResize); ok {
+				// Nothing else redraws an idle app, so a resize needs a frame of its own.
+				app.screen.Sync()
+				app.RequestRender()
 				continue
 			}
-			var startNode *node
-
-			switch e := event.(type) {
-			case *tcell.EventKey:
-				startNode = getNodeWithFocusOrRoot(app, tree)
-			case *tcell.EventMouse:
-				x, y := e.Position()
-				startNode = findDeepestNodeAtPosition(tree, x, y)
-
-			default:
-				startNode = tree
+			current := app.scene.Load()
+			if current == nil || interceptPalette(app, event) {
+				continue
+			}
+			startNode := current.startNode(app, event)
+			if startNode == nil {
+				current.clickOutside(event)
+				continue
 			}
 
 			handlers := make(map[string]func(tcell.Event) bool)
@@ -55,17 +62,37 @@ func dispatch(app *App) {
 			app.managers.event.mu.Unlock()
 			// Bubble up from the starting node
 			for n := startNode; n != nil; n = n.parent {
-				if handler, ok := handlers[n.id]; !ok {
+				handler, ok := handlers[n.id]
+				if !ok {
 					continue
-				} else if handled := handler(event); handled {
-					app.channels.render <- struct{}{}
+				}
+				handled, boundary, err := recoverHandler(app.managers.state, n, func() bool { return handler(event) })
+				if err != nil {
+					app.managers.lifecycle.crash(err)
+					return
+				}
+				if boundary != nil {
+					app.scheduler.invalidate(componentID(boundary.id))
+					break
+				}
+				if handled {
+					app.scheduler.invalidate(componentID(n.id))
 					break
 				}
 			}
+		case fn := <-app.channels.call:
+			fn()
 		}
 	}
 }
 
+// isPrimaryClick reports whether the event is a press of the primary mouse
+// button. Releases and other buttons are ignored.
+func isPrimaryClick(event tcell.Event) bool {
+	mouse, ok := event.(*tcell.EventMouse)
+	return ok && mouse.Buttons()&tcell.Button1 != 0
+}
+
 func findDeepestNodeAtPosition(root *node, x, y int) *node {
 	var found *node
 
@@ -84,24 +111,25 @@ func findDeepestNodeAtPosition(root *node, x, y int) *node {
 }
 
 func pointInBounds(x, y int, bounds *box) bool {
+	if bounds == nil {
+		return false
+	}
 	return x >= bounds.x && x < bounds.x+bounds.width &&
 		y >= bounds.y && y < bounds.y+bounds.height
 }
 
-func getNodeWithFocusOrRoot(app *App, tree *node) *node {
+// getNodeWithFocus returns the node of the focused component if it is part
+// of the given tree, or nil otherwise.
+func getNodeWithFocus(app *App, tree *node) *node {
 	app.managers.focus.mu.Lock()
 	defer app.managers.focus.mu.Unlock()
 
 	focus := app.managers.focus.focused
 	if focus == "" {
-		return tree
+		return nil
 	}
 
-	if node := findNodeByID(tree, focus); node == nil {
-		return tree
-	} else {
-		return node
-	}
+	return findNodeByID(tree, focus)
 }
 
 // findNodeByID searches the tree recursively for a node with the given componentID.
@@ -125,6 +153,12 @@ func findNodeByID(n *node, id componentID) *node {
 // further up the tree, or false if it should continue bubbling to parent components.
 //
 // Handlers are keyed by the component's ID (`ctx.id`) and are stored in the global event manager.
+// They are dropped when the component is unmounted.
+//
+// Handling an event renders the handler's component again, along with its
+// subtree, but not the rest of the tree. Handlers that change anything else
+// should do it through state that invalidates its readers, such as UseState
+// setters and atoms, or call App.RequestRender.
 // Thread-safe.
 func UseEvent(ctx *Context, handler func(event tcell.Event) bool) {
 	manager := ctx.managers.event
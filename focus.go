@@ -76,6 +76,61 @@ func (f *focusManager) unregister(fid focusableID) {
 	}
 }
 
+// prune drops the focusable IDs of the components that are not in use, and
+// clears focus if the focused component is one of them.
+//
+// Thread-safe.
+func (f *focusManager) prune(inUse func(id componentID) bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for fid, cid := range f.registered {
+		if !inUse(cid) {
+			delete(f.registered, fid)
+		}
+	}
+	for cid := range f.inverse {
+		if !inUse(cid) {
+			delete(f.inverse, cid)
+		}
+	}
+	if f.focused != "" && !inUse(f.focused) {
+		f.focused = ""
+	}
+}
+
+// focus moves focus to the component owning the given focusableID and
+// returns the components that gained or lost focus, to be rendered again.
+// Nothing is returned if focus did not change; unknown IDs are ignored.
+//
+// Thread-safe.
+func (f *focusManager) focus(fid focusableID) []componentID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id, ok := f.registered[fid]
+	if !ok || id == f.focused {
+		return nil
+	}
+	changed := []componentID{id}
+	if f.focused != "" {
+		changed = append(changed, f.focused)
+	}
+	f.focused = id
+	return changed
+}
+
+// focusedID returns the focusableID registered by the focused component, or
+// an empty ID if nothing is focused.
+//
+// Thread-safe.
+func (f *focusManager) focusedID() focusableID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.focused == "" {
+		return ""
+	}
+	return f.inverse[f.focused]
+}
+
 // UseFocus registers a focusable element for the current component and
 // returns focus helpers.
 //
@@ -116,11 +171,8 @@ func UseFocus(ctx *Context, id string) (isFocused bool, setIsFocused func(id str
 	manager.inverse[componentID(ctx.id)] = focusableID(id)
 
 	setIsFocused = func(newID string) {
-		manager.mu.Lock()
-		defer manager.mu.Unlock()
-		if id, ok := manager.registered[focusableID(newID)]; ok && id != manager.focused {
-			manager.focused = id
-			ctx.channels.render <- struct{}{}
+		if changed := manager.focus(focusableID(newID)); changed != nil {
+			ctx.scheduler.invalidate(changed...)
 		}
 	}
 
@@ -128,8 +180,8 @@ func UseFocus(ctx *Context, id string) (isFocused bool, setIsFocused func(id str
 		manager.mu.Lock()
 		defer manager.mu.Unlock()
 		if manager.focused != "" {
+			ctx.scheduler.invalidate(manager.focused)
 			manager.focused = ""
-			ctx.channels.render <- struct{}{}
 		}
 	}
 
@@ -0,0 +1,697 @@
+package matcha
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// FormValues holds the values of a form's fields, keyed by field name.
+//
+// Values are typed by field kind: text fields hold a string, number fields a
+// float64, select and radio fields the selected option as a string, checkbox
+// fields a bool and multi-select fields a []string. The typed getters return
+// the zero value for missing fields and mismatched types alike; FormValue
+// tells the two apart.
+type FormValues map[string]any
+
+// String returns the value of a text, select or radio field, or "" if the
+// field is missing or holds another type.
+func (v FormValues) String(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+// Float returns the value of a number field, or 0 if the field is missing or
+// holds another type.
+func (v FormValues) Float(name string) float64 {
+	f, _ := v[name].(float64)
+	return f
+}
+
+// Int returns the value of a number field, truncated to an int, or 0 if the
+// field is missing or holds another type.
+func (v FormValues) Int(name string) int {
+	return int(v.Float(name))
+}
+
+// Bool returns the value of a checkbox field, or false if the field is
+// missing or holds another type.
+func (v FormValues) Bool(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+// Strings returns the value of a multi-select field, or nil if the field is
+// missing or holds another type.
+func (v FormValues) Strings(name string) []string {
+	s, _ := v[name].([]string)
+	return s
+}
+
+// FormValueError is the error FormValue returns for a field that is missing
+// or holds a value of another type.
+type FormValueError struct {
+	Name string
+	// Value is the value the field holds, or nil if it is missing.
+	Value any
+	// Want is the type that was asked for.
+	Want reflect.Type
+}
+
+func (e *FormValueError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("form: field %q has no value", e.Name)
+	}
+	return fmt.Sprintf("form: field %q holds a %T, not a %s", e.Name, e.Value, e.Want)
+}
+
+// FormValue returns the value of a field as a T. Unlike the typed getters of
+// FormValues, it reports a missing field or a value of another type, with a
+// *FormValueError:
+//
+//	port, err := FormValue[float64](values, "port")
+func FormValue[T any](values FormValues, name string) (T, error) {
+	value, ok := values[name].(T)
+	if !ok {
+		return value, &FormValueError{Name: name, Value: values[name], Want: reflect.TypeFor[T]()}
+	}
+	return value, nil
+}
+
+// Validator checks the value of a single field and returns an error message,
+// or an empty string if the value is valid. The value has the type described
+// in FormValues, or is nil if the field has no value yet.
+type Validator func(value any) string
+
+// Required rejects missing and empty values: empty strings and slices, and
+// unchecked checkboxes.
+func Required(message string) Validator {
+	return func(value any) string {
+		if value == nil {
+			return message
+		}
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.String, reflect.Slice:
+			if v.Len() == 0 {
+				return message
+			}
+		case reflect.Bool:
+			if !v.Bool() {
+				return message
+			}
+		}
+		return ""
+	}
+}
+
+// MinLength rejects text shorter than n runes.
+func MinLength(n int, message string) Validator {
+	return func(value any) string {
+		if s, _ := value.(string); len([]rune(s)) < n {
+			return message
+		}
+		return ""
+	}
+}
+
+// Range rejects numbers outside of [low, high].
+func Range(low, high float64, message string) Validator {
+	return func(value any) string {
+		if f, ok := value.(float64); ok && (f < low || f > high) {
+			return message
+		}
+		return ""
+	}
+}
+
+// formData is the snapshot of a form held by its atom. It is replaced, never
+// mutated, on every update.
+type formData struct {
+	values FormValues
+	// drafts holds the raw text of number fields, which may not parse yet.
+	drafts map[string]string
+	errors map[string]string
+}
+
+// clone returns a deep copy of the maps of the snapshot.
+func (d formData) clone() formData {
+	return formData{
+		values: maps.Clone(d.values),
+		drafts: maps.Clone(d.drafts),
+		errors: maps.Clone(d.errors),
+	}
+}
+
+// formCount gives every FormState a unique ID.
+var formCount atomic.Int64
+
+// FormState is the state shared by a Form and its fields: the values, the
+// validation errors and the validators registered by the fields.
+//
+// It is backed by an Atom, so every field rerenders when the form changes.
+// A FormState can be created anywhere, typically next to the atoms of the
+// screen that owns the form.
+type FormState struct {
+	id         string
+	initial    FormValues
+	atom       *Atom[formData]
+	validators map[string]Validator
+	mu         sync.Mutex
+}
+
+// NewFormState creates a form state with the given initial values, which may
+// be nil.
+func NewFormState(initial FormValues) *FormState {
+	f := &FormState{
+		id:         fmt.Sprintf("form-%d", formCount.Add(1)),
+		initial:    maps.Clone(initial),
+		validators: make(map[string]Validator),
+	}
+	f.atom = &Atom[formData]{ID: f.id, Value: f.initialData()}
+	return f
+}
+
+// initialData returns a fresh snapshot holding the initial values.
+func (f *FormState) initialData() formData {
+	values := maps.Clone(f.initial)
+	if values == nil {
+		values = FormValues{}
+	}
+	return formData{values: values, drafts: map[string]string{}, errors: map[string]string{}}
+}
+
+// Values returns a copy of the current values.
+func (f *FormState) Values() FormValues {
+	return maps.Clone(f.atom.value().values)
+}
+
+// Errors returns a copy of the current validation errors, keyed by field name.
+func (f *FormState) Errors() map[string]string {
+	return maps.Clone(f.atom.value().errors)
+}
+
+// Set changes the value of a field and validates it.
+func (f *FormState) Set(name string, value any) {
+	f.change(name, func(FormValues) any { return value })
+}
+
+// change sets a field to the value computed by `fn` from the current values,
+// rather than from the values a field was rendered with, and validates it.
+func (f *FormState) change(name string, fn func(values FormValues) any) {
+	validate := f.validator(name)
+	f.atom.update(func(old formData) formData {
+		next := old.clone()
+		value := fn(old.values)
+		next.values[name] = value
+		delete(next.drafts, name)
+		setError(next.errors, name, validate(value))
+		return next
+	})
+}
+
+// Reset restores the initial values and clears all errors.
+func (f *FormState) Reset() {
+	f.atom.update(func(formData) formData {
+		return f.initialData()
+	})
+}
+
+// edit applies a key to the text of a text or number field, with the cursor
+// at `cursor`, and validates the result. The key is applied to the current
+// text rather than to the text the field was rendered with, so keys arriving
+// faster than frames are never lost. It returns the new cursor position.
+func (f *FormState) edit(name string, number bool, cursor int, key *tcell.EventKey) int {
+	validate := f.validator(name)
+	f.atom.update(func(old formData) formData {
+		text := textOf(old, name)
+		text.cursor = min(cursor, len(text.runes))
+		text, _ = text.edit(key)
+		cursor = text.cursor
+
+		next := old.clone()
+		if number {
+			setDraft(next, name, text.String(), validate)
+		} else {
+			next.values[name] = text.String()
+			delete(next.drafts, name)
+			setError(next.errors, name, validate(text.String()))
+		}
+		return next
+	})
+	return cursor
+}
+
+// setDraft stores the raw text of a number field in a snapshot being
+// updated, updating its value when the text parses.
+func setDraft(next formData, name, text string, validate Validator) {
+	next.drafts[name] = text
+	if text == "" {
+		delete(next.values, name)
+		setError(next.errors, name, validate(nil))
+		return
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		delete(next.values, name)
+		next.errors[name] = "Must be a number"
+		return
+	}
+	next.values[name] = number
+	setError(next.errors, name, validate(number))
+}
+
+// register records the validator of a field. Fields register on every render.
+func (f *FormState) register(name string, validate Validator) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validators[name] = validate
+}
+
+// unregister forgets the validator and the error of a field that is no
+// longer shown, so that it cannot prevent the form from being submitted.
+func (f *FormState) unregister(name string) {
+	f.mu.Lock()
+	delete(f.validators, name)
+	f.mu.Unlock()
+
+	f.atom.update(func(old formData) formData {
+		next := old.clone()
+		delete(next.errors, name)
+		return next
+	})
+}
+
+// validator returns the validator of a field, or one accepting everything.
+func (f *FormState) validator(name string) Validator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if validate := f.validators[name]; validate != nil {
+		return validate
+	}
+	return func(any) string { return "" }
+}
+
+// validate runs every field validator, then the cross-field validator if
+// there is one, and stores the resulting errors. It reports whether the form
+// is valid. Number fields whose text does not parse stay invalid.
+func (f *FormState) validate(cross func(values FormValues) map[string]string) bool {
+	f.mu.Lock()
+	validators := maps.Clone(f.validators)
+	f.mu.Unlock()
+
+	valid := true
+	f.atom.update(func(old formData) formData {
+		next := old.clone()
+		for name, validate := range validators {
+			if _, ok := next.drafts[name]; ok && next.values[name] == nil && next.drafts[name] != "" {
+				next.errors[name] = "Must be a number"
+				continue
+			}
+			setError(next.errors, name, validate(next.values[name]))
+		}
+		if cross != nil {
+			for name, message := range cross(maps.Clone(next.values)) {
+				if message != "" && next.errors[name] == "" {
+					next.errors[name] = message
+				}
+			}
+		}
+		valid = len(next.errors) == 0
+		return next
+	})
+	return valid
+}
+
+// fieldID returns the focusable ID of a field.
+func (f *FormState) fieldID(name string) string {
+	return f.id + "/" + name
+}
+
+// setError stores or clears the error of a field.
+func setError(errors map[string]string, name, message string) {
+	if message == "" {
+		delete(errors, name)
+	} else {
+		errors[name] = message
+	}
+}
+
+// FormProps configures a Form.
+type FormProps struct {
+	State *FormState
+	// Fields are the form's fields, created with TextField, NumberField,
+	// SelectField, CheckboxField, RadioField or MultiSelectField. Other
+	// components may be mixed in and are skipped by keyboard navigation.
+	Fields []Component
+	// Validate checks the form as a whole on submission and returns error
+	// messages keyed by field name. Field validators run first; a field keeps
+	// its own error if both report one.
+	Validate func(values FormValues) map[string]string
+	// OnSubmit is called with the values once the form is submitted and valid.
+	// Read them with the typed getters of FormValues, or with FormValue to
+	// catch a field read with the wrong type.
+	OnSubmit func(values FormValues)
+	Style    lipgloss.Style
+}
+
+type form struct {
+	props FormProps
+}
+
+// Form lays out its fields in a column and handles navigation and
+// submission.
+//
+// Tab and Shift+Tab move focus through the fields in order, and Enter
+// submits the form, unless the focused field uses the key itself. On
+// submission every validator runs; if any field is invalid, focus moves to
+// the first invalid field instead of calling OnSubmit.
+func Form(props FormProps) Component {
+	return &form{props: props}
+}
+
+func (f *form) Render(ctx *Context) Component {
+	manager := ctx.managers.focus
+	state := f.props.State
+
+	var names []string
+	for _, field := range f.props.Fields {
+		if field, ok := field.(*formField); ok {
+			names = append(names, field.props.Name)
+		}
+	}
+
+	focusField := func(name string) {
+		if changed := manager.focus(focusableID(state.fieldID(name))); changed != nil {
+			ctx.scheduler.invalidate(changed...)
+		}
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok || len(names) == 0 {
+			return false
+		}
+
+		current := slices.IndexFunc(names, func(name string) bool {
+			return focusableID(state.fieldID(name)) == manager.focusedID()
+		})
+
+		switch key.Key() {
+		case tcell.KeyTab:
+			focusField(names[(current+1)%len(names)])
+		case tcell.KeyBacktab:
+			if current < 0 {
+				current = 0
+			}
+			focusField(names[(current-1+len(names))%len(names)])
+		case tcell.KeyEnter:
+			if state.validate(f.props.Validate) {
+				if f.props.OnSubmit != nil {
+					f.props.OnSubmit(state.Values())
+				}
+				return true
+			}
+			errors := state.Errors()
+			if index := slices.IndexFunc(names, func(name string) bool { return errors[name] != "" }); index >= 0 {
+				focusField(names[index])
+			}
+		default:
+			return false
+		}
+		return true
+	})
+
+	return Column(f.props.Fields, f.props.Style)
+}
+
+// FieldProps configures a form field.
+type FieldProps struct {
+	// Name identifies the field in FormValues. Must be unique within the form.
+	Name  string
+	Label string
+	// Placeholder is shown by empty text and number fields.
+	Placeholder string
+	// Options are the choices of select, radio and multi-select fields.
+	Options  []string
+	Validate Validator
+	Style    lipgloss.Style
+}
+
+// fieldKind selects the control drawn by a form field.
+type fieldKind int
+
+const (
+	fieldText fieldKind = iota
+	fieldNumber
+	fieldSelect
+	fieldCheckbox
+	fieldRadio
+	fieldMultiSelect
+)
+
+type formField struct {
+	form  *FormState
+	kind  fieldKind
+	props FieldProps
+}
+
+// TextField is a single-line text input. Its value is a string.
+func TextField(form *FormState, props FieldProps) Component {
+	return &formField{form: form, kind: fieldText, props: props}
+}
+
+// NumberField is a text input accepting a number. Its value is a float64,
+// and it is reported as invalid while its text does not parse.
+func NumberField(form *FormState, props FieldProps) Component {
+	return &formField{form: form, kind: fieldNumber, props: props}
+}
+
+// SelectField cycles through its options with Left/Right or Space. Its value
+// is the selected option.
+func SelectField(form *FormState, props FieldProps) Component {
+	return &formField{form: form, kind: fieldSelect, props: props}
+}
+
+// CheckboxField is toggled with Space or a click. Its value is a bool.
+func CheckboxField(form *FormState, props FieldProps) Component {
+	return &formField{form: form, kind: fieldCheckbox, props: props}
+}
+
+// RadioField lists its options and selects one with the arrow keys. Its
+// value is the selected option.
+func RadioField(form *FormState, props FieldProps) Component {
+	return &formField{form: form, kind: fieldRadio, props: props}
+}
+
+// MultiSelectField lists its options; Up/Down move the cursor and Space
+// toggles the option under it. Its value is the list of selected options, in
+// the order of Options.
+func MultiSelectField(form *FormState, props FieldProps) Component {
+	return &formField{form: form, kind: fieldMultiSelect, props: props}
+}
+
+func (f *formField) Key() string {
+	return f.props.Name
+}
+
+func (f *formField) Render(ctx *Context) Component {
+	name := f.props.Name
+	data := UseAtomValue(ctx, f.form.atom)
+	isFocused, setFocus, _ := UseFocus(ctx, f.form.fieldID(name))
+	text := textOf(data, name)
+	// The cursor is the position in the text of text and number fields,
+	// starting at its end, and the highlighted option of multi-select ones.
+	initialCursor := 0
+	if f.kind == fieldText || f.kind == fieldNumber {
+		initialCursor = len(text.runes)
+	}
+	cursor, setCursor, getCursor := useState(ctx, initialCursor)
+
+	validate := f.props.Validate
+	if validate == nil {
+		validate = func(any) string { return "" }
+	}
+	f.form.register(name, validate)
+	registered := useRef(ctx, false)
+	if !*registered {
+		*registered = true
+		ctx.managers.lifecycle.onUnmount(componentID(ctx.id), func() {
+			*registered = false
+			f.form.unregister(name)
+		})
+	}
+
+	value := data.values[name]
+	text.cursor = min(cursor, len(text.runes))
+	options := f.props.Options
+
+	// Several events may be handled before the next frame, so the handler
+	// works from the current values and cursor rather than the rendered ones.
+	toggle := func() {
+		f.form.change(name, func(values FormValues) any { return !values.Bool(name) })
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if isPrimaryClick(event) {
+			setFocus(f.form.fieldID(name))
+			if f.kind == fieldCheckbox {
+				toggle()
+			}
+			return true
+		}
+		key, ok := event.(*tcell.EventKey)
+		if !ok {
+			return false
+		}
+
+		switch f.kind {
+		case fieldText, fieldNumber:
+			if !isEditKey(key) {
+				return false
+			}
+			cursor := f.form.edit(name, f.kind == fieldNumber, getCursor(), key)
+			setCursor(func(int) int { return cursor })
+		case fieldCheckbox:
+			if key.Key() != tcell.KeyRune || key.Rune() != ' ' {
+				return false
+			}
+			toggle()
+		case fieldSelect, fieldRadio:
+			if len(options) == 0 {
+				return false
+			}
+			var offset int
+			switch {
+			case key.Key() == tcell.KeyRight, key.Key() == tcell.KeyDown,
+				key.Key() == tcell.KeyRune && key.Rune() == ' ' && f.kind == fieldSelect:
+				offset = 1
+			case key.Key() == tcell.KeyLeft, key.Key() == tcell.KeyUp:
+				offset = -1
+			default:
+				return false
+			}
+			f.form.change(name, func(values FormValues) any {
+				current := slices.Index(options, values.String(name))
+				if current < 0 && offset < 0 {
+					current = 0
+				}
+				return options[(current+offset+len(options))%len(options)]
+			})
+		case fieldMultiSelect:
+			switch {
+			case key.Key() == tcell.KeyDown:
+				setCursor(func(c int) int { return min(c+1, len(options)-1) })
+			case key.Key() == tcell.KeyUp:
+				setCursor(func(c int) int { return max(c-1, 0) })
+			case key.Key() == tcell.KeyRune && key.Rune() == ' ':
+				cursor := getCursor()
+				if cursor >= len(options) {
+					return false
+				}
+				f.form.change(name, func(values FormValues) any {
+					return toggleOption(options, values.Strings(name), options[cursor])
+				})
+			default:
+				return false
+			}
+		}
+		return true
+	})
+
+	labelStyle := lipgloss.NewStyle().Bold(isFocused)
+	var control Component
+	switch f.kind {
+	case fieldText, fieldNumber:
+		control = renderTextValue(text, f.props.Placeholder, isFocused, f.props.Style)
+	case fieldSelect:
+		selected := data.values.String(name)
+		if selected == "" {
+			selected = f.props.Placeholder
+		}
+		control = Text("‹ "+selected+" ›", f.props.Style.Reverse(isFocused))
+	case fieldCheckbox:
+		return Column([]Component{
+			Text(checkGlyph(data.values.Bool(name))+" "+f.props.Label, f.props.Style.Bold(isFocused)),
+			fieldError(data.errors[name]),
+		}, lipgloss.NewStyle().MarginBottom(1))
+	case fieldRadio:
+		rows := make([]Component, 0, len(options))
+		for _, option := range options {
+			rows = append(rows, Text(radioGlyph(value == option)+" "+option, f.props.Style))
+		}
+		control = Column(rows, lipgloss.NewStyle())
+	case fieldMultiSelect:
+		selected := data.values.Strings(name)
+		rows := make([]Component, 0, len(options))
+		for i, option := range options {
+			rows = append(rows, Text(checkGlyph(slices.Contains(selected, option))+" "+option,
+				f.props.Style.Reverse(isFocused && i == cursor)))
+		}
+		control = Column(rows, lipgloss.NewStyle())
+	}
+
+	return Column([]Component{
+		Text(f.props.Label, labelStyle),
+		control,
+		fieldError(data.errors[name]),
+	}, lipgloss.NewStyle().MarginBottom(1))
+}
+
+// textOf returns the editable text of a text or number field.
+func textOf(data formData, name string) textValue {
+	if draft, ok := data.drafts[name]; ok {
+		return textValue{runes: []rune(draft)}
+	}
+	switch value := data.values[name].(type) {
+	case string:
+		return textValue{runes: []rune(value)}
+	case float64:
+		return textValue{runes: []rune(strconv.FormatFloat(value, 'f', -1, 64))}
+	}
+	return textValue{}
+}
+
+// fieldError draws the error message of a field, or nothing if it is valid.
+func fieldError(message string) Component {
+	if message == "" {
+		return Column(nil, lipgloss.NewStyle())
+	}
+	return Text(message, lipgloss.NewStyle().Foreground(SeverityError.color()))
+}
+
+// toggleOption adds or removes `option` from `selected`, keeping the result
+// in the order of `options`.
+func toggleOption(options, selected []string, option string) []string {
+	result := make([]string, 0, len(selected)+1)
+	for _, o := range options {
+		if (o == option) != slices.Contains(selected, o) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// checkGlyph returns the glyph of a checkbox.
+func checkGlyph(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// radioGlyph returns the glyph of a radio button.
+func radioGlyph(selected bool) string {
+	if selected {
+		return "(•)"
+	}
+	return "( )"
+}
@@ -0,0 +1,85 @@
+package matcha
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+type formScreen struct {
+	state     *FormState
+	extra     *bool
+	submitted *FormValues
+}
+
+func (c *formScreen) Render(ctx *Context) Component {
+	fields := []Component{TextField(c.state, FieldProps{Name: "name", Validate: Required("required")})}
+	if *c.extra {
+		fields = append(fields, TextField(c.state, FieldProps{Name: "email", Validate: Required("required")}))
+	}
+	return Form(FormProps{
+		State:    c.state,
+		Fields:   fields,
+		OnSubmit: func(values FormValues) { *c.submitted = values },
+	})
+}
+
+func TestFormFieldKeysBeforeFrame(t *testing.T) {
+	extra := false
+	var submitted FormValues
+	state := NewFormState(FormValues{"name": "ab"})
+	app := newTestApp(t, &formScreen{state: state, extra: &extra, submitted: &submitted})
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	// The cursor starts at the end of the initial value.
+	for _, r := range "cde" {
+		press(t, app, "root/0/0/#name", tcell.KeyRune, r)
+	}
+	press(t, app, "root/0/0/#name", tcell.KeyBackspace2, 0)
+	if got := state.Values().String("name"); got != "abcd" {
+		t.Errorf("value %q, want %q", got, "abcd")
+	}
+}
+
+func TestFormUnmountedFieldValidation(t *testing.T) {
+	extra := true
+	var submitted FormValues
+	state := NewFormState(FormValues{"name": "ab"})
+	app := newTestApp(t, &formScreen{state: state, extra: &extra, submitted: &submitted})
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	press(t, app, "root/0", tcell.KeyEnter, 0)
+	if submitted != nil {
+		t.Fatal("submitted with an empty required field")
+	}
+
+	extra = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+	press(t, app, "root/0", tcell.KeyEnter, 0)
+	if submitted == nil {
+		t.Error("a field that is no longer shown prevents submission")
+	}
+}
+
+func TestFormValue(t *testing.T) {
+	values := FormValues{"name": "matcha", "port": 8080.0}
+
+	if port, err := FormValue[float64](values, "port"); err != nil || port != 8080 {
+		t.Errorf("FormValue[float64](port) = %v, %v; want 8080, nil", port, err)
+	}
+
+	var valueErr *FormValueError
+	if _, err := FormValue[string](values, "port"); !errors.As(err, &valueErr) || valueErr.Value != 8080.0 {
+		t.Errorf("FormValue[string](port) error = %v, want a mismatched type", err)
+	}
+	if _, err := FormValue[bool](values, "missing"); !errors.As(err, &valueErr) || valueErr.Value != nil {
+		t.Errorf("FormValue[bool](missing) error = %v, want a missing field", err)
+	}
+	if got := values.String("port"); got != "" {
+		t.Errorf(`String(port) = %q, want ""`, got)
+	}
+}
@@ -0,0 +1,54 @@
+package matcha
+
+import (
+	"unicode"
+)
+
+// fuzzyMatch reports whether every rune of `pattern` appears in `text` in
+// order, ignoring case.
+//
+// On a match it returns a score, higher being better, and the matched
+// positions as half-open [start, end) rune ranges, with adjacent positions
+// merged into a single range. Matches are rewarded for being consecutive and
+// for starting words, and penalized for the gaps between them.
+//
+// An empty pattern matches everything with a score of zero.
+func fuzzyMatch(pattern, text string) (score int, ranges [][2]int, ok bool) {
+	needle := []rune(pattern)
+	haystack := []rune(text)
+	if len(needle) == 0 {
+		return 0, nil, true
+	}
+
+	last := -1
+	matched := 0
+	for i := 0; i < len(haystack) && matched < len(needle); i++ {
+		if unicode.ToLower(haystack[i]) != unicode.ToLower(needle[matched]) {
+			continue
+		}
+
+		if last >= 0 && i == last+1 {
+			score += 8
+			ranges[len(ranges)-1][1] = i + 1
+		} else {
+			if last >= 0 {
+				score -= min(i-last-1, 4)
+			}
+			ranges = append(ranges, [2]int{i, i + 1})
+		}
+		if i == 0 || (!unicode.IsLetter(haystack[i-1]) && !unicode.IsDigit(haystack[i-1])) {
+			score += 6
+		} else if unicode.IsUpper(haystack[i]) && unicode.IsLower(haystack[i-1]) {
+			score += 4
+		}
+		score++
+
+		last = i
+		matched++
+	}
+
+	if matched < len(needle) {
+		return 0, nil, false
+	}
+	return score, ranges, true
+}
@@ -0,0 +1,51 @@
+package matcha
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, text string
+		ranges        [][2]int
+		ok            bool
+	}{
+		{"", "anything", nil, true},
+		{"save", "Save file", [][2]int{{0, 4}}, true},
+		{"SF", "save file", [][2]int{{0, 1}, {5, 6}}, true},
+		{"of", "Open file", [][2]int{{0, 1}, {5, 6}}, true},
+		{"gt", "goToLine", [][2]int{{0, 1}, {2, 3}}, true},
+		{"éa", "Éclair au", [][2]int{{0, 1}, {3, 4}}, true},
+		{"fs", "save file", nil, false},
+		{"saves", "save", nil, false},
+		{"x", "", nil, false},
+	} {
+		_, ranges, ok := fuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(ranges, tt.ranges) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %t, want %v, %t", tt.pattern, tt.text, ranges, ok, tt.ranges, tt.ok)
+		}
+	}
+}
+
+// TestFuzzyMatchScore checks that matches are ranked by how well they fit.
+func TestFuzzyMatchScore(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, better, worse string
+	}{
+		// Consecutive over scattered.
+		{"file", "Open file", "Find in list of entries"},
+		// Word starts over word middles.
+		{"of", "Open file", "Proof"},
+		// Camel case humps over word middles.
+		{"tl", "goToLine", "gotoline"},
+		// Small gaps over large ones.
+		{"ab", "a-b", "a------b"},
+	} {
+		better, _, _ := fuzzyMatch(tt.pattern, tt.better)
+		worse, _, _ := fuzzyMatch(tt.pattern, tt.worse)
+		if better <= worse {
+			t.Errorf("fuzzyMatch(%q) scored %q %d and %q %d, want the first higher", tt.pattern, tt.better, better, tt.worse, worse)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package matcha
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HistoryOptions configures NewHistory.
+type HistoryOptions struct {
+	// Limit is the maximum number of steps that can be undone. Defaults to
+	// 100; the oldest steps are dropped first.
+	Limit int
+	// GroupWithin merges updates made less than this apart into a single
+	// step, so that typing a word is undone at once rather than letter by
+	// letter. Defaults to 500ms; a negative value disables grouping.
+	GroupWithin time.Duration
+}
+
+// History records the updates of an atom and lets them be undone and
+// redone.
+//
+// Every update of the atom, wherever it is made from, is recorded: the
+// setters returned by UseAtomState need no change. Recorded values are kept
+// as they are, so values sharing memory, such as slices and maps, must be
+// replaced rather than modified in place for undo to restore them.
+type History[T any] struct {
+	atom    *Atom[T]
+	options HistoryOptions
+
+	past     []T
+	future   []T
+	current  T         // Value of the atom as last recorded.
+	last     time.Time // When the last step was recorded; zero once it is closed for grouping.
+	group    int       // Nesting depth of Group calls.
+	grouped  bool      // Whether the current Group already recorded a step.
+	mu       sync.Mutex
+	applying atomic.Bool // Whether the atom is being set by Undo or Redo.
+	applied  int         // Version of the atom last set by Undo or Redo.
+	applyMu  sync.Mutex  // Serializes Undo and Redo.
+
+	canUndo *Atom[bool]
+	canRedo *Atom[bool]
+}
+
+// NewHistory starts recording the updates of `atom`:
+//
+//	text := NewAtom("")
+//	history := NewHistory(text, HistoryOptions{})
+//	...
+//	canUndo := UseAtomValue(ctx, history.CanUndo())
+//
+// The history keeps a persistent subscription on the atom, so it is meant to
+// be created once, alongside the atom, rather than during a render.
+func NewHistory[T any](atom *Atom[T], options HistoryOptions) *History[T] {
+	if options.Limit <= 0 {
+		options.Limit = 100
+	}
+	if options.GroupWithin == 0 {
+		options.GroupWithin = 500 * time.Millisecond
+	}
+	h := &History[T]{
+		atom:    atom,
+		options: options,
+		current: atom.Get(),
+		canUndo: NewAtom(false),
+		canRedo: NewAtom(false),
+	}
+	atom.watch(h.record)
+	return h
+}
+
+// record records an update of the atom, either as a new step or merged into
+// the last one. Values set by Undo and Redo are not recorded: they are
+// recognized while being applied, or by their version when a Batch defers
+// the notification until after apply returned.
+//
+// Thread-safe.
+func (h *History[T]) record() {
+	value, version := h.atom.snapshot()
+
+	h.mu.Lock()
+	if h.applying.Load() || version == h.applied {
+		h.current = value
+		h.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	var merge bool
+	if h.group > 0 {
+		merge, h.grouped = h.grouped, true
+	} else {
+		merge = h.options.GroupWithin > 0 && !h.last.IsZero() && now.Sub(h.last) < h.options.GroupWithin
+	}
+	if !merge {
+		h.past = append(h.past, h.current)
+		if len(h.past) > h.options.Limit {
+			h.past = h.past[len(h.past)-h.options.Limit:]
+		}
+	}
+	h.current = value
+	h.future = nil
+	if h.group == 0 {
+		h.last = now
+	}
+	h.mu.Unlock()
+
+	h.publish()
+}
+
+// Group runs `fn` and records all the updates it makes to the atom as a
+// single step. Groups can be nested.
+func (h *History[T]) Group(fn func()) {
+	h.mu.Lock()
+	if h.group == 0 {
+		h.grouped = false
+		h.last = time.Time{}
+	}
+	h.group++
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		h.group--
+		h.mu.Unlock()
+	}()
+	fn()
+}
+
+// Undo restores the atom to its value before the last step. It reports
+// whether there was a step to undo.
+//
+// Thread-safe.
+func (h *History[T]) Undo() bool {
+	return h.apply(&h.past, &h.future)
+}
+
+// Redo applies the last undone step again. It reports whether there was a
+// step to redo. Any update made since the last Undo clears the steps that
+// could be redone.
+//
+// Thread-safe.
+func (h *History[T]) Redo() bool {
+	return h.apply(&h.future, &h.past)
+}
+
+// apply moves the atom to the last value of `from`, pushing its current
+// value onto `to`.
+func (h *History[T]) apply(from, to *[]T) bool {
+	h.applyMu.Lock()
+	defer h.applyMu.Unlock()
+
+	h.mu.Lock()
+	if len(*from) == 0 {
+		h.mu.Unlock()
+		return false
+	}
+	value := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, h.current)
+	h.current = value
+	h.last = time.Time{}
+	h.mu.Unlock()
+
+	h.applying.Store(true)
+	version := h.atom.updateVersion(func(T) T { return value })
+	h.applying.Store(false)
+	if version != 0 {
+		h.mu.Lock()
+		h.applied = version
+		h.mu.Unlock()
+	}
+
+	h.publish()
+	return true
+}
+
+// Clear forgets every recorded step.
+//
+// Thread-safe.
+func (h *History[T]) Clear() {
+	h.mu.Lock()
+	h.past, h.future = nil, nil
+	h.last = time.Time{}
+	h.mu.Unlock()
+
+	h.publish()
+}
+
+// CanUndo returns an atom telling whether there is a step to undo, e.g. to
+// disable an Undo button.
+func (h *History[T]) CanUndo() *Atom[bool] {
+	return h.canUndo
+}
+
+// CanRedo returns an atom telling whether there is a step to redo.
+func (h *History[T]) CanRedo() *Atom[bool] {
+	return h.canRedo
+}
+
+// publish updates the CanUndo and CanRedo atoms. Their subscribers are only
+// notified when they change.
+func (h *History[T]) publish() {
+	h.mu.Lock()
+	canUndo, canRedo := len(h.past) > 0, len(h.future) > 0
+	h.mu.Unlock()
+
+	h.canUndo.update(func(bool) bool { return canUndo })
+	h.canRedo.update(func(bool) bool { return canRedo })
+}
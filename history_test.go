@@ -0,0 +1,57 @@
+package matcha
+
+import "testing"
+
+// TestHistoryBatch checks that undoing and redoing inside a Batch, which
+// defers the notification recording the update, moves through the steps
+// rather than recording new ones.
+func TestHistoryBatch(t *testing.T) {
+	atom := NewAtom(0)
+	history := NewHistory(atom, HistoryOptions{GroupWithin: -1})
+	for i := 1; i <= 3; i++ {
+		Batch(func() { atom.update(func(int) int { return i }) })
+	}
+
+	for _, want := range []int{2, 1} {
+		Batch(func() { history.Undo() })
+		if got := atom.Get(); got != want {
+			t.Fatalf("after Undo, value = %d, want %d", got, want)
+		}
+	}
+	Batch(func() { history.Redo() })
+	if got := atom.Get(); got != 2 {
+		t.Fatalf("after Redo, value = %d, want 2", got)
+	}
+	if !history.CanRedo().Get() {
+		t.Error("CanRedo() = false after undoing twice and redoing once")
+	}
+	Batch(func() { history.Undo() })
+	Batch(func() { history.Undo() })
+	if got := atom.Get(); got != 0 {
+		t.Errorf("after undoing every step, value = %d, want 0", got)
+	}
+	if history.CanUndo().Get() {
+		t.Error("CanUndo() = true after undoing every step")
+	}
+}
+
+// TestHistoryUpdateAfterUndo checks that an update made in the same Batch as
+// an Undo is recorded and clears the steps to redo.
+func TestHistoryUpdateAfterUndo(t *testing.T) {
+	atom := NewAtom(0)
+	history := NewHistory(atom, HistoryOptions{GroupWithin: -1})
+	atom.update(func(int) int { return 1 })
+	atom.update(func(int) int { return 2 })
+
+	Batch(func() {
+		history.Undo()
+		atom.update(func(int) int { return 5 })
+	})
+	if history.CanRedo().Get() {
+		t.Error("CanRedo() = true after an update")
+	}
+	history.Undo()
+	if got := atom.Get(); got != 1 {
+		t.Errorf("after Undo, value = %d, want 1", got)
+	}
+}
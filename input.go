@@ -0,0 +1,103 @@
+package matcha
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// textValue is the content of a single-line text input together with the
+// position of its cursor, counted in runes.
+type textValue struct {
+	runes  []rune
+	cursor int
+}
+
+// newTextValue returns a textValue holding `s` with the cursor at its end.
+func newTextValue(s string) textValue {
+	runes := []rune(s)
+	return textValue{runes: runes, cursor: len(runes)}
+}
+
+func (t textValue) String() string {
+	return string(t.runes)
+}
+
+// edit applies a key event to the value. It returns the updated value and
+// whether the key was an editing key. The receiver is never modified.
+//
+// Supported keys: printable characters, Backspace, Delete, Left, Right,
+// Home/Ctrl+A, End/Ctrl+E and Ctrl+U (clear up to the cursor).
+func (t textValue) edit(key *tcell.EventKey) (textValue, bool) {
+	runes := make([]rune, len(t.runes))
+	copy(runes, t.runes)
+	cursor := min(max(t.cursor, 0), len(runes))
+
+	switch key.Key() {
+	case tcell.KeyRune:
+		if key.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+			return t, false
+		}
+		runes = append(runes[:cursor], append([]rune{key.Rune()}, runes[cursor:]...)...)
+		cursor++
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if cursor == 0 {
+			return t, true
+		}
+		runes = append(runes[:cursor-1], runes[cursor:]...)
+		cursor--
+	case tcell.KeyDelete:
+		if cursor < len(runes) {
+			runes = append(runes[:cursor], runes[cursor+1:]...)
+		}
+	case tcell.KeyLeft:
+		cursor = max(cursor-1, 0)
+	case tcell.KeyRight:
+		cursor = min(cursor+1, len(runes))
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		cursor = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		cursor = len(runes)
+	case tcell.KeyCtrlU:
+		runes = runes[cursor:]
+		cursor = 0
+	default:
+		return t, false
+	}
+
+	return textValue{runes: runes, cursor: cursor}, true
+}
+
+// isEditKey reports whether edit handles the key, whatever the value. Event
+// handlers use it to decide whether a key is theirs before applying it to
+// the latest value.
+func isEditKey(key *tcell.EventKey) bool {
+	_, ok := textValue{}.edit(key)
+	return ok
+}
+
+// renderTextValue draws the value on a single line. When `focused` is true
+// the cell under the cursor is drawn reversed; an empty, unfocused value
+// shows the placeholder instead.
+func renderTextValue(value textValue, placeholder string, focused bool, style lipgloss.Style) Component {
+	if !focused {
+		if len(value.runes) == 0 && placeholder != "" {
+			return Text(placeholder, style.Faint(true))
+		}
+		return Text(value.String(), style)
+	}
+
+	cursor := min(max(value.cursor, 0), len(value.runes))
+	under := " "
+	after := ""
+	if cursor < len(value.runes) {
+		under = string(value.runes[cursor])
+		after = string(value.runes[cursor+1:])
+	}
+
+	inline := inlineStyle(style)
+	return Row([]Component{
+		Text(string(value.runes[:cursor]), inline),
+		Text(under, inline.Reverse(true)),
+		Text(after, inline),
+	}, style)
+}
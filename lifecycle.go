@@ -0,0 +1,186 @@
+package matcha
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// lifecycleManager tracks which components are mounted, that is present in
+// the last frame's scene, and runs the cleanups of the components that
+// disappear from it. Components may retain the IDs of subtrees they do not
+// render, so that the local state of those subtrees outlives them being
+// unmounted. It also owns the context of the application's
+// lifetime, cancelled when the application quits, and reports the panics
+// that no ErrorBoundary recovered.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type lifecycleManager struct {
+	mounted  map[componentID]struct{}
+	cleanups map[componentID]map[int]func()
+	next     int                           // Key of the next cleanup.
+	retained map[componentID][]componentID // Owner ID to the IDs of the subtrees it retains.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	crashed  chan *PanicError // Receives the first panic that should end the application.
+	mu       sync.Mutex
+}
+
+// newLifecycleManager creates and returns a new lifecycleManager with
+// nothing mounted.
+func newLifecycleManager() *lifecycleManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &lifecycleManager{
+		mounted:  make(map[componentID]struct{}),
+		cleanups: make(map[componentID]map[int]func()),
+		retained: make(map[componentID][]componentID),
+		ctx:      ctx,
+		cancel:   cancel,
+		crashed:  make(chan *PanicError, 1),
+	}
+}
+
+// context returns a context cancelled when the application quits, or when
+// the returned function is called.
+func (l *lifecycleManager) context() (context.Context, context.CancelFunc) {
+	return context.WithCancel(l.ctx)
+}
+
+// stop cancels the context of the application's lifetime.
+func (l *lifecycleManager) stop() {
+	l.cancel()
+}
+
+// crash reports a panic that ends the application. Render restores the
+// terminal and panics again with it. Only the first panic is kept.
+//
+// Thread-safe.
+func (l *lifecycleManager) crash(err *PanicError) {
+	select {
+	case l.crashed <- err:
+	default:
+	}
+}
+
+// guard runs `fn`, typically the body of a goroutine, and reports its panic
+// with crash. Without it, a panic on a goroutine other than Render's would
+// end the process with the terminal still in raw mode.
+func (l *lifecycleManager) guard(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.crash(newPanicError(r))
+		}
+	}()
+	fn()
+}
+
+// onUnmount registers `fn` to run when the component is unmounted, and
+// returns a function unregistering it. Cleanups run once, on the build loop.
+//
+// Thread-safe.
+func (l *lifecycleManager) onUnmount(id componentID, fn func()) (remove func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.next++
+	key := l.next
+	if l.cleanups[id] == nil {
+		l.cleanups[id] = make(map[int]func())
+	}
+	l.cleanups[id][key] = fn
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.cleanups[id], key)
+	}
+}
+
+// retain keeps the local state of the subtrees rooted at `ids` while they
+// are unmounted, for as long as `owner` is mounted. Each call replaces the
+// IDs retained by the owner; calling it with no ID releases them.
+//
+// Thread-safe.
+func (l *lifecycleManager) retain(owner componentID, ids ...componentID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(ids) == 0 {
+		delete(l.retained, owner)
+	} else {
+		l.retained[owner] = ids
+	}
+}
+
+// commit records the components of a new scene as mounted and runs the
+// cleanups of those that were mounted in the previous one but are not
+// anymore. It returns a function telling whether the state of a component
+// must be kept, because it is mounted or retained, to prune the state of
+// every other component.
+//
+// Thread-safe.
+func (l *lifecycleManager) commit(s *scene) (inUse func(id componentID) bool) {
+	mounted := make(map[componentID]struct{}, len(l.mounted))
+	collectIDs(s.root, mounted)
+	for _, layer := range s.layers {
+		collectIDs(layer.tree, mounted)
+	}
+
+	l.mu.Lock()
+	var cleanups []func()
+	for id := range l.mounted {
+		if _, ok := mounted[id]; ok {
+			continue
+		}
+		for _, fn := range l.cleanups[id] {
+			cleanups = append(cleanups, fn)
+		}
+		delete(l.cleanups, id)
+	}
+	l.mounted = mounted
+
+	var retained []componentID
+	for owner, ids := range l.retained {
+		if _, ok := mounted[owner]; !ok {
+			delete(l.retained, owner)
+			continue
+		}
+		retained = append(retained, ids...)
+	}
+	l.mu.Unlock()
+
+	for _, fn := range cleanups {
+		fn()
+	}
+
+	return func(id componentID) bool {
+		if _, ok := mounted[id]; ok {
+			return true
+		}
+		for _, root := range retained {
+			if id == root || strings.HasPrefix(string(id), string(root)+"/") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// prune drops the state kept for the components that are not in use, as
+// reported by commit: their local state, event handlers, focusable IDs,
+// commands and context consumers. Without it, every component ever mounted,
+// such as the rows of a list or the toasts shown so far, would keep its
+// state for the life of the application, and a component mounted later with
+// the same ID would inherit it.
+func (m *managers) prune(inUse func(id componentID) bool) {
+	m.state.prune(inUse)
+	m.event.prune(inUse)
+	m.focus.prune(inUse)
+	m.command.prune(inUse)
+	m.context.prune(inUse)
+}
+
+// collectIDs adds the IDs of every node of the tree to `ids`.
+func collectIDs(tree *node, ids map[componentID]struct{}) {
+	ids[componentID(tree.id)] = struct{}{}
+	for _, child := range tree.children {
+		collectIDs(child, ids)
+	}
+}
@@ -0,0 +1,107 @@
+package matcha
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// hasState reports whether some component below `root` has local state.
+func hasState(app *App, root componentID) bool {
+	app.managers.state.mu.Lock()
+	defer app.managers.state.mu.Unlock()
+	for id := range app.managers.state.slots {
+		if id == root || strings.HasPrefix(string(id), string(root)+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+type handled struct{}
+
+func (c *handled) Render(ctx *Context) Component {
+	UseState(ctx, 0)
+	UseEvent(ctx, func(tcell.Event) bool { return true })
+	return Text("handled", lipgloss.NewStyle())
+}
+
+func TestUnmountPrunesState(t *testing.T) {
+	show := true
+	app := newTestApp(t, &shown{show: &show, child: &handled{}})
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if !hasState(app, "root/0") {
+		t.Fatal("mounted component has no state")
+	}
+
+	show = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if hasState(app, "root/0") {
+		t.Error("state of an unmounted component was kept")
+	}
+	if _, ok := app.managers.event.handlers["root/0"]; ok {
+		t.Error("handler of an unmounted component was kept")
+	}
+}
+
+type shown struct {
+	show  *bool
+	child Component
+}
+
+func (c *shown) Render(ctx *Context) Component {
+	if !*c.show {
+		return Text("hidden", lipgloss.NewStyle(), "hidden")
+	}
+	return c.child
+}
+
+type focusable struct{}
+
+func (c *focusable) Render(ctx *Context) Component {
+	UseFocus(ctx, "focusable")
+	return Text("focusable", lipgloss.NewStyle())
+}
+
+func TestUnmountClearsFocus(t *testing.T) {
+	show := true
+	app := newTestApp(t, &shown{show: &show, child: &focusable{}})
+	app.scheduler.invalidate()
+	drawFrame(app)
+	app.managers.focus.focus("focusable")
+
+	show = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if id := app.managers.focus.focusedID(); id != "" {
+		t.Errorf("focused %q after unmount, want nothing", id)
+	}
+	if _, ok := app.managers.focus.registered["focusable"]; ok {
+		t.Error("focusable ID of an unmounted component was kept")
+	}
+}
+
+func TestTabsInactivePanels(t *testing.T) {
+	app := newTestApp(t, Tabs(TabsProps{
+		ID: "tabs",
+		Tabs: []Tab{
+			{Key: "a", Title: "A", Content: &handled{}},
+			{Key: "b", Title: "B", Content: &handled{}},
+		},
+	}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+	press(t, app, "root", tcell.KeyRune, '2')
+	drawFrame(app)
+
+	if !hasState(app, panelID("root", "b")) {
+		t.Error("active panel has no state")
+	}
+	if !hasState(app, panelID("root", "a")) {
+		t.Error("state of the inactive panel was dropped")
+	}
+}
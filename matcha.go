@@ -1,43 +1,60 @@
 package matcha
 
 import (
+	"sync/atomic"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gdamore/tcell/v2"
 	"github.com/muesli/termenv"
 )
 
 type channels struct {
-	event  chan tcell.Event
-	tree   chan *node
-	quit   chan struct{}
-	render chan struct{}
+	event chan tcell.Event
+	call  chan func() // Callbacks run by the event dispatcher, serialized with event handlers.
+	quit  chan struct{}
 }
 
 type managers struct {
-	focus *focusManager
-	event *eventManager
+	focus     *focusManager
+	event     *eventManager
+	state     *stateManager
+	overlay   *overlayManager
+	toast     *toastManager
+	command   *commandManager
+	clock     *clock
+	context   *contextManager
+	lifecycle *lifecycleManager
 }
 
 type App struct {
-	root     Component
-	screen   tcell.Screen
-	channels *channels
-	managers *managers
+	root      Component
+	screen    tcell.Screen
+	channels  *channels
+	managers  *managers
+	scheduler *scheduler
+	scene     atomic.Pointer[scene] // The last scene drawn, used to dispatch events.
 }
 
 func NewApp(component Component) *App {
 	return &App{
 		root: component,
 		channels: &channels{
-			event:  make(chan tcell.Event, 1),
-			tree:   make(chan *node, 1),
-			quit:   make(chan struct{}, 1),
-			render: make(chan struct{}, 1),
+			event: make(chan tcell.Event, 1),
+			call:  make(chan func()),
+			quit:  make(chan struct{}, 1),
 		},
 		managers: &managers{
-			focus: newFocusManager(),
-			event: newEventManager(),
+			focus:     newFocusManager(),
+			event:     newEventManager(),
+			state:     newStateManager(),
+			overlay:   newOverlayManager(),
+			toast:     newToastManager(),
+			command:   newCommandManager(),
+			clock:     newClock(),
+			context:   newContextManager(),
+			lifecycle: newLifecycleManager(),
 		},
+		scheduler: newScheduler(defaultMaxFPS),
 	}
 }
 
@@ -54,24 +71,38 @@ func (a *App) Render() error {
 	if err := screen.Init(); err != nil {
 		return err
 	}
+	screen.EnableMouse(tcell.MouseButtonEvents)
 
 	go screen.ChannelEvents(a.channels.event, a.channels.quit)
 
-	go dispatch(a)
+	lifecycle := a.managers.lifecycle
+	go lifecycle.guard(func() { dispatch(a) })
 
-	a.channels.render <- struct{}{}
+	a.scheduler.invalidate()
 
-	go build(a)
+	go lifecycle.guard(func() { build(a) })
 
-	<-a.channels.quit
+	select {
+	case <-a.channels.quit:
+	case err := <-lifecycle.crashed:
+		// Restore the terminal first, or the report would be unreadable.
+		lifecycle.stop()
+		screen.Fini()
+		err.report()
+		panic(err.Value)
+	}
+	lifecycle.stop()
 
 	return nil
 }
 
-func (a *App) newContext(id string) *Context {
+func (a *App) newContext(id string, node *node, dirty *invalidation) *Context {
 	return &Context{
-		id:       id,
-		channels: a.channels,
-		managers: a.managers,
+		id:        id,
+		node:      node,
+		dirty:     dirty,
+		channels:  a.channels,
+		managers:  a.managers,
+		scheduler: a.scheduler,
 	}
 }
@@ -0,0 +1,104 @@
+package matcha
+
+import "reflect"
+
+// Memoizable is implemented by components that can tell whether they would
+// render the same output as the component they replace.
+//
+// When a parent renders again, its children are new instances and are
+// normally rendered again too. A child implementing Memoizable is compared
+// with the instance it replaces at the same position in the tree instead,
+// and if Equal returns true, its previous subtree is reused without calling
+// Render. The child is still rendered again when its own state changes.
+//
+// Go functions cannot be compared, so Equal usually ignores callback props;
+// parents keep those stable with UseCallback.
+type Memoizable interface {
+	Equal(other Component) bool
+}
+
+// memo is the component returned by Memo and MemoFunc.
+type memo[P any] struct {
+	props  P
+	render func(ctx *Context, props P) Component
+	equal  func(a, b P) bool
+}
+
+// Memo turns a render function into a component that is only rendered again
+// when its props change, as compared with ==, or when its own state does.
+//
+//	row := Memo(rowProps{Label: item.Label, Selected: i == cursor}, renderRow)
+func Memo[P comparable](props P, render func(ctx *Context, props P) Component) Component {
+	return &memo[P]{props: props, render: render, equal: func(a, b P) bool { return a == b }}
+}
+
+// MemoFunc is like Memo for props that are not comparable with ==, such as
+// structs holding slices or callbacks, using `equal` to compare them.
+func MemoFunc[P any](props P, render func(ctx *Context, props P) Component, equal func(a, b P) bool) Component {
+	return &memo[P]{props: props, render: render, equal: equal}
+}
+
+func (m *memo[P]) Render(ctx *Context) Component {
+	return m.render(ctx, m.props)
+}
+
+func (m *memo[P]) Equal(other Component) bool {
+	o, ok := other.(*memo[P])
+	return ok && m.equal(m.props, o.props)
+}
+
+// memoState is the bookkeeping of UseMemo.
+type memoState[T any] struct {
+	value T
+	deps  []any
+	set   bool
+}
+
+// UseMemo returns the value computed by `compute`, computing it again only
+// when one of `deps` changed since the last render. Dependencies are
+// compared with ==; those that are not comparable, such as slices and maps,
+// are treated as changed on every render.
+//
+//	visible := UseMemo(ctx, func() []Item { return filter(items, query) }, query, version)
+//
+// Like all slot-based hooks, UseMemo must be called unconditionally and in
+// the same order on every render.
+func UseMemo[T any](ctx *Context, compute func() T, deps ...any) T {
+	state := useRef(ctx, memoState[T]{})
+	if !state.set || !depsEqual(state.deps, deps) {
+		*state = memoState[T]{value: compute(), deps: deps, set: true}
+	}
+	return state.value
+}
+
+// UseCallback returns `fn` as it was when one of `deps` last changed, so
+// that the callback passed to memoized children stays the same across
+// renders. Dependencies are compared as in UseMemo.
+//
+//	onSelect := UseCallback(ctx, func(key string) { setSelected(func(string) string { return key }) })
+func UseCallback[F any](ctx *Context, fn F, deps ...any) F {
+	return UseMemo(ctx, func() F { return fn }, deps...)
+}
+
+// depsEqual reports whether two dependency lists are equal.
+func depsEqual(a, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equalValues(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues compares two values with ==, without panicking on values that
+// are not comparable, which are never equal.
+func equalValues(a, b any) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		return va.IsValid() == vb.IsValid()
+	}
+	return va.Comparable() && va.Equal(vb)
+}
@@ -0,0 +1,260 @@
+package matcha
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// placement computes the top-left corner of an overlay of the given size on
+// a screen of the given size.
+type placement func(screenWidth, screenHeight, width, height int) (x, y int)
+
+// placeCenter centers the overlay on the screen.
+func placeCenter(screenWidth, screenHeight, width, height int) (int, int) {
+	return max((screenWidth-width)/2, 0), max((screenHeight-height)/2, 0)
+}
+
+// placeTopCenter centers the overlay horizontally, a fifth of the way down
+// the screen.
+func placeTopCenter(screenWidth, screenHeight, width, height int) (int, int) {
+	return max((screenWidth-width)/2, 0), screenHeight / 5
+}
+
+// placeBottomRight puts the overlay in the bottom-right corner of the screen,
+// one cell away from its edges.
+func placeBottomRight(screenWidth, screenHeight, width, height int) (int, int) {
+	return max(screenWidth-width-1, 0), max(screenHeight-height-1, 0)
+}
+
+// overlayOptions describes how an overlay is positioned and how it treats
+// input.
+type overlayOptions struct {
+	place placement
+	// anchor is the ID of a component the overlay is attached to. When set,
+	// the overlay is drawn right below that component (or above it if there
+	// is not enough room) and `place` is ignored.
+	anchor string
+	// modal overlays trap keyboard and mouse input until they are removed.
+	modal bool
+	// dim fades everything beneath the overlay.
+	dim bool
+	// onOutsideClick is called when a modal overlay is clicked outside of.
+	onOutsideClick func()
+}
+
+// overlay is a component drawn on top of the root component, such as a
+// dialog or a dropdown.
+type overlay struct {
+	overlayOptions
+	id        string
+	component Component
+}
+
+// overlayManager keeps the stack of open overlays, bottom-most first.
+//
+// Overlay IDs double as the component IDs of the overlays' root nodes, so
+// the hooks used by an overlay (state, focus, events) are scoped to it.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type overlayManager struct {
+	layers []*overlay
+	next   int
+	mu     sync.Mutex
+}
+
+// newOverlayManager creates and returns a new, empty overlayManager.
+func newOverlayManager() *overlayManager {
+	return &overlayManager{}
+}
+
+// push opens a new overlay on top of the stack and returns its ID.
+//
+// Thread-safe.
+func (o *overlayManager) push(component Component, options overlayOptions) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.next++
+	id := fmt.Sprintf("overlay/%d", o.next)
+	o.layers = append(o.layers, &overlay{overlayOptions: options, id: id, component: component})
+	return id
+}
+
+// remove closes the overlay with the given ID. It is a no-op if the overlay
+// was already removed.
+//
+// Thread-safe.
+func (o *overlayManager) remove(id string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	index := slices.IndexFunc(o.layers, func(layer *overlay) bool { return layer.id == id })
+	if index < 0 {
+		return false
+	}
+	o.layers = slices.Delete(o.layers, index, index+1)
+	return true
+}
+
+// snapshot returns a copy of the current stack.
+//
+// Thread-safe.
+func (o *overlayManager) snapshot() []*overlay {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return slices.Clone(o.layers)
+}
+
+// layer is an overlay walked and laid out for a frame.
+type layer struct {
+	*overlay
+	tree *node
+}
+
+// scene is everything drawn in a frame: the root component's tree and the
+// overlays stacked on top of it, bottom-most first.
+type scene struct {
+	root   *node
+	layers []layer
+}
+
+// compose walks the root component and every open overlay, reusing the
+// parts of the previous scene that were not invalidated.
+func compose(app *App, dirty *invalidation) *scene {
+	var prevRoot *node
+	prevLayers := make(map[string]*node)
+	if prev := app.scene.Load(); prev != nil {
+		prevRoot = prev.root
+		for _, l := range prev.layers {
+			prevLayers[l.id] = l.tree
+		}
+	}
+
+	s := &scene{root: walk(app, app.root, "root", nil, prevRoot, false, dirty)}
+	for _, o := range app.managers.overlay.snapshot() {
+		s.layers = append(s.layers, layer{overlay: o, tree: walk(app, o.component, o.id, nil, prevLayers[o.id], false, dirty)})
+	}
+	return s
+}
+
+// paint lays out the scene and draws it onto a screen-sized box. Overlays
+// are positioned through their placement or anchor, and everything beneath
+// a dimming overlay is faded.
+func paint(s *scene, width, height int) *box {
+	canvas := &box{width: width, height: height, grid: make([][]character, height)}
+	for i := range canvas.grid {
+		canvas.grid[i] = make([]character, width)
+		for j := range canvas.grid[i] {
+			canvas.grid[i][j] = character{ch: ' ', style: tcell.StyleDefault}
+		}
+	}
+
+	canvas.copyInto(pack(s.root, 0, 0))
+	for i, l := range s.layers {
+		if l.dim {
+			canvas.dim()
+		}
+		b := pack(l.tree, 0, 0)
+		var x, y int
+		if l.anchor != "" {
+			x, y = s.placeAnchored(i, l.anchor, height, b.height)
+		} else {
+			x, y = l.place(width, height, b.width, b.height)
+		}
+		if x != 0 || y != 0 {
+			b = pack(l.tree, x, y)
+		}
+		canvas.copyInto(b)
+	}
+
+	return canvas
+}
+
+// placeAnchored positions the layer at index `i` below the component with
+// the given ID, or above it if it does not fit below. The component is
+// searched in the root tree and the layers beneath. Overlays whose anchor is
+// not on screen are drawn at the top-left corner.
+func (s *scene) placeAnchored(i int, anchor string, screenHeight, height int) (int, int) {
+	target := findNodeByID(s.root, componentID(anchor))
+	for j := 0; target == nil && j < i; j++ {
+		target = findNodeByID(s.layers[j].tree, componentID(anchor))
+	}
+	if target == nil || target.box == nil {
+		return 0, 0
+	}
+
+	x, y := target.box.x, target.box.y+target.box.height
+	if y+height > screenHeight && target.box.y-height >= 0 {
+		y = target.box.y - height
+	}
+	return x, y
+}
+
+// dim fades every cell of the box, used as the backdrop of modal overlays.
+func (b *box) dim() {
+	for _, row := range b.grid {
+		for i := range row {
+			row[i].style = row[i].style.Dim(true)
+		}
+	}
+}
+
+// topModal returns the index of the top-most modal layer, or -1 if there is
+// none.
+func (s *scene) topModal() int {
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if s.layers[i].modal {
+			return i
+		}
+	}
+	return -1
+}
+
+// clickOutside notifies the top-most modal layer that it was clicked
+// outside of, if the event is a click. It is used for events for which
+// startNode found no node.
+func (s *scene) clickOutside(event tcell.Event) {
+	if modal := s.topModal(); modal >= 0 && isPrimaryClick(event) && s.layers[modal].onOutsideClick != nil {
+		s.layers[modal].onOutsideClick()
+	}
+}
+
+// startNode returns the node an event starts bubbling from.
+//
+// Keyboard events start at the focused node, mouse events at the deepest
+// node under the pointer, searching overlays top-most first. While a modal
+// overlay is open, events can only reach the modal overlay and the overlays
+// above it: keyboard events fall back to the modal's root, and mouse events
+// outside of it are dropped (nil is returned).
+func (s *scene) startNode(app *App, event tcell.Event) *node {
+	modal := s.topModal()
+
+	trees := []*node{s.root}
+	if modal >= 0 {
+		trees = nil
+	}
+	for _, l := range s.layers[max(modal, 0):] {
+		trees = append(trees, l.tree)
+	}
+
+	switch e := event.(type) {
+	case *tcell.EventKey:
+		for i := len(trees) - 1; i >= 0; i-- {
+			if n := getNodeWithFocus(app, trees[i]); n != nil {
+				return n
+			}
+		}
+		return trees[0]
+	case *tcell.EventMouse:
+		x, y := e.Position()
+		for i := len(trees) - 1; i >= 0; i-- {
+			if n := findDeepestNodeAtPosition(trees[i], x, y); n != nil {
+				return n
+			}
+		}
+		return nil
+	default:
+		return trees[0]
+	}
+}
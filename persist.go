@@ -0,0 +1,397 @@
+package matcha
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Codec encodes and decodes the values of persisted atoms.
+type Codec interface {
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// Built-in codecs.
+var (
+	// JSON stores values as indented JSON, honoring `json` struct tags.
+	JSON Codec = jsonCodec{}
+	// Gob stores values in the compact binary gob format.
+	Gob Codec = gobCodec{}
+	// TOML stores values as TOML-like `key = value` lines, with nested
+	// objects written as [tables]. Values are written as JSON literals, so
+	// strings, numbers, booleans and arrays of those are valid TOML; values
+	// follow the `json` struct tags. Comments, blank lines and dotted keys
+	// are accepted when decoding, but inline comments and multi-line values
+	// are not supported.
+	TOML Codec = tomlCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Encode(w io.Writer, v any) error {
+	return gob.NewEncoder(w).Encode(v)
+}
+
+func (gobCodec) Decode(r io.Reader, v any) error {
+	return gob.NewDecoder(r).Decode(v)
+}
+
+// tomlCodec goes through the JSON representation of values: encoding turns
+// it into tables, and decoding turns tables back into JSON.
+type tomlCodec struct{}
+
+// bareKey matches the keys written without quotes.
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func (tomlCodec) Encode(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var table map[string]any
+	if err := decoder.Decode(&table); err != nil {
+		return fmt.Errorf("toml: top-level value must be an object: %w", err)
+	}
+	buffer := bufio.NewWriter(w)
+	if err := writeTable(buffer, nil, table); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
+
+// writeTable writes the values of a table, then its nested tables.
+func writeTable(w *bufio.Writer, path []string, table map[string]any) error {
+	var values, tables []string
+	for key, value := range table {
+		if _, ok := value.(map[string]any); ok {
+			tables = append(tables, key)
+		} else {
+			values = append(values, key)
+		}
+	}
+	slices.Sort(values)
+	slices.Sort(tables)
+
+	for _, key := range values {
+		literal, err := json.Marshal(table[key])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s = %s\n", tomlKey(key), literal)
+	}
+	for _, key := range tables {
+		nested := append(slices.Clone(path), tomlKey(key))
+		fmt.Fprintf(w, "\n[%s]\n", strings.Join(nested, "."))
+		if err := writeTable(w, nested, table[key].(map[string]any)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tomlKey returns the key as written in a TOML document, quoted if needed.
+func tomlKey(key string) string {
+	if bareKey.MatchString(key) {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+func (tomlCodec) Decode(r io.Reader, v any) error {
+	root := make(map[string]any)
+	table := root
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			keys, rest, err := parseTomlKeys(strings.TrimSpace(text[1 : len(text)-1]))
+			if err != nil || rest != "" {
+				return fmt.Errorf("toml: line %d: invalid table header", line)
+			}
+			table = nestedTable(root, keys)
+			continue
+		}
+
+		keys, rest, err := parseTomlKeys(text)
+		if err != nil || !strings.HasPrefix(rest, "=") {
+			return fmt.Errorf("toml: line %d: expected key = value", line)
+		}
+		var value any
+		decoder := json.NewDecoder(strings.NewReader(strings.TrimSpace(rest[1:])))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("toml: line %d: invalid value: %w", line, err)
+		}
+		// Dotted keys, as in `window.width = 80`, set a value of a nested
+		// table.
+		nestedTable(table, keys[:len(keys)-1])[keys[len(keys)-1]] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// nestedTable returns the table found by following `keys` from `table`,
+// creating the missing ones. A value in the way is replaced by a table.
+func nestedTable(table map[string]any, keys []string) map[string]any {
+	for _, key := range keys {
+		nested, ok := table[key].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			table[key] = nested
+		}
+		table = nested
+	}
+	return table
+}
+
+// parseTomlKeys parses a dotted key made of bare or quoted keys, returning
+// the keys and the rest of the text with leading spaces trimmed.
+func parseTomlKeys(text string) ([]string, string, error) {
+	var keys []string
+	for {
+		text = strings.TrimLeft(text, " \t")
+		var key string
+		if strings.HasPrefix(text, `"`) {
+			decoder := json.NewDecoder(strings.NewReader(text))
+			if err := decoder.Decode(&key); err != nil {
+				return nil, "", err
+			}
+			text = text[decoder.InputOffset():]
+		} else {
+			end := strings.IndexFunc(text, func(r rune) bool {
+				return !(r == '_' || r == '-' || r >= '0' && r <= '9' || r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z')
+			})
+			if end < 0 {
+				end = len(text)
+			}
+			if end == 0 {
+				return nil, "", errors.New("toml: empty key")
+			}
+			key, text = text[:end], text[end:]
+		}
+		keys = append(keys, key)
+
+		text = strings.TrimLeft(text, " \t")
+		if !strings.HasPrefix(text, ".") {
+			return keys, text, nil
+		}
+		text = text[1:]
+	}
+}
+
+// PersistOptions configures Persist.
+type PersistOptions[T any] struct {
+	// Codec defaults to JSON.
+	Codec Codec
+	// Debounce is how long writes wait for further updates, so that bursts
+	// of updates are written once. Defaults to 250ms.
+	Debounce time.Duration
+	// Version is the schema version of T, stored alongside the value.
+	Version int
+	// Migrate is called when the file was written with another Version. It
+	// receives that version and a function decoding the stored value into
+	// the value `v` points to, typically the old version of T, and returns
+	// the value to load. Without Migrate, values are decoded into T as they
+	// are, which tolerates added and removed fields with codecs that ignore
+	// unknown fields, such as JSON and TOML.
+	Migrate func(version int, decode func(v any) error) (T, error)
+	// OnError is called with the errors of background writes.
+	OnError func(err error)
+}
+
+// persister writes an atom to its file.
+type persister[T any] struct {
+	atom    *Atom[T]
+	path    string
+	options PersistOptions[T]
+	timer   *time.Timer // Pending debounced write, if any.
+	mu      sync.Mutex
+}
+
+// Persist binds an atom to a file, typically to remember preferences or
+// layout across runs:
+//
+//	theme := NewAtom(Theme{Dark: true})
+//	flush, err := Persist(theme, filepath.Join(configDir, "theme.toml"), PersistOptions[Theme]{Codec: TOML})
+//	defer flush()
+//
+// The atom is first loaded from the file, if it exists. Fields missing from
+// the file keep the atom's current value, so it should be called with the
+// atom holding its defaults, before the application renders. Afterwards,
+// every update of the atom is written to the file after the debounce delay.
+// Files are written atomically, by renaming a complete temporary file over
+// the previous one, so a crash never leaves a truncated file behind.
+//
+// Persist returns an error if the file exists but cannot be loaded; the atom
+// is bound nonetheless, and the next update replaces the file. The returned
+// flush function writes any pending update immediately and should be called
+// before the application exits.
+func Persist[T any](atom *Atom[T], path string, options PersistOptions[T]) (flush func() error, err error) {
+	if options.Codec == nil {
+		options.Codec = JSON
+	}
+	if options.Debounce <= 0 {
+		options.Debounce = 250 * time.Millisecond
+	}
+	p := &persister[T]{atom: atom, path: path, options: options}
+
+	err = p.load()
+	atom.watch(p.schedule)
+	return p.flush, err
+}
+
+// load reads the file into the atom. A missing file is not an error.
+func (p *persister[T]) load() error {
+	data, err := os.ReadFile(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	codec := p.options.Codec
+	value := p.atom.Get()
+	version, err := decodeEnvelope(codec, data, &value)
+	if version != p.options.Version && p.options.Migrate != nil {
+		value, err = p.options.Migrate(version, func(v any) error {
+			_, err := decodeEnvelope(codec, data, v)
+			return err
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("loading %s: %w", p.path, err)
+	}
+
+	p.atom.update(func(T) T { return value })
+	return nil
+}
+
+// schedule writes the atom after the debounce delay, postponing any write
+// already scheduled.
+//
+// Thread-safe.
+func (p *persister[T]) schedule() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.timer = time.AfterFunc(p.options.Debounce, func() {
+		if err := p.flush(); err != nil && p.options.OnError != nil {
+			p.options.OnError(err)
+		}
+	})
+}
+
+// flush writes the atom now if a write is pending.
+//
+// Thread-safe.
+func (p *persister[T]) flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer == nil {
+		return nil
+	}
+	p.timer.Stop()
+	p.timer = nil
+	return p.write()
+}
+
+// write encodes the atom into a temporary file next to the target, then
+// renames it over the target. Must be called with the lock held.
+func (p *persister[T]) write() error {
+	value := p.atom.Get()
+	dir := filepath.Dir(p.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(p.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	err = encodeEnvelope(p.options.Codec, file, p.options.Version, &value)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", p.path, err)
+	}
+	return os.Rename(file.Name(), p.path)
+}
+
+// envelope returns a new struct holding a schema version and a value of the
+// type pointed to by `value`, which is how persisted values are stored.
+func envelope(value any) reflect.Value {
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "Version", Type: reflect.TypeFor[int](), Tag: `json:"version"`},
+		{Name: "Value", Type: reflect.TypeOf(value).Elem(), Tag: `json:"value"`},
+	})
+	return reflect.New(typ)
+}
+
+// encodeEnvelope encodes the value pointed to by `value` along with its
+// schema version.
+func encodeEnvelope(codec Codec, w io.Writer, version int, value any) error {
+	e := envelope(value)
+	e.Elem().Field(0).SetInt(int64(version))
+	e.Elem().Field(1).Set(reflect.ValueOf(value).Elem())
+	return codec.Encode(w, e.Interface())
+}
+
+// decodeEnvelope decodes a stored value into the value pointed to by
+// `value`, starting from its current content, and returns its schema
+// version.
+func decodeEnvelope(codec Codec, data []byte, value any) (int, error) {
+	e := envelope(value)
+	e.Elem().Field(1).Set(reflect.ValueOf(value).Elem())
+	if err := codec.Decode(bytes.NewReader(data), e.Interface()); err != nil {
+		return int(e.Elem().Field(0).Int()), err
+	}
+	reflect.ValueOf(value).Elem().Set(e.Elem().Field(1))
+	return int(e.Elem().Field(0).Int()), nil
+}
@@ -0,0 +1,164 @@
+package matcha
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type tomlSettings struct {
+	Name   string         `json:"name"`
+	Width  int            `json:"width"`
+	Ratio  float64        `json:"ratio"`
+	Tags   []string       `json:"tags"`
+	Window tomlWindow     `json:"window"`
+	Keys   map[string]int `json:"keys"`
+}
+
+type tomlWindow struct {
+	Maximized bool     `json:"maximized"`
+	Position  [2]int   `json:"position"`
+	Pane      tomlPane `json:"pane"`
+}
+
+type tomlPane struct {
+	Split float64 `json:"split"`
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	want := tomlSettings{
+		Name:  "line\nbreak \"quoted\"",
+		Width: 80,
+		Ratio: 0.25,
+		Tags:  []string{"a", "b"},
+		Window: tomlWindow{
+			Maximized: true,
+			Position:  [2]int{10, -3},
+			Pane:      tomlPane{Split: 0.5},
+		},
+		Keys: map[string]int{"a.b": 1, "with space": 2, `quo"te`: 3, "": 4, "é": 5, "bare_key-1": 6},
+	}
+	var buffer bytes.Buffer
+	if err := TOML.Encode(&buffer, want); err != nil {
+		t.Fatal(err)
+	}
+	var got tomlSettings
+	if err := TOML.Decode(bytes.NewReader(buffer.Bytes()), &got); err != nil {
+		t.Fatalf("decoding\n%s: %v", buffer.String(), err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip through\n%s\ngot  %+v\nwant %+v", buffer.String(), got, want)
+	}
+}
+
+func TestTOMLDecodeKeys(t *testing.T) {
+	const document = `
+# Comment
+title = "app"
+window.width = 80
+"quoted.key" = 1
+
+[theme]
+colors."accent color" = "#61AFEF"
+
+[ "a.b" . c ]
+d = true
+`
+	var got map[string]any
+	if err := TOML.Decode(strings.NewReader(document), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"title":      "app",
+		"window":     map[string]any{"width": float64(80)},
+		"quoted.key": float64(1),
+		"theme":      map[string]any{"colors": map[string]any{"accent color": "#61AFEF"}},
+		"a.b":        map[string]any{"c": map[string]any{"d": true}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+}
+
+func TestTOMLDecodeErrors(t *testing.T) {
+	for _, document := range []string{
+		"key",
+		"= 1",
+		"key = ",
+		"key = 'single'",
+		"[table",
+		"[]",
+		`"unterminated = 1`,
+	} {
+		var got map[string]any
+		if err := TOML.Decode(strings.NewReader(document), &got); err == nil {
+			t.Errorf("decoding %q succeeded with %v, want an error", document, got)
+		}
+	}
+}
+
+type settingsV1 struct {
+	Dark bool `json:"dark"`
+}
+
+type settingsV2 struct {
+	Theme string `json:"theme"`
+	Width int    `json:"width"`
+}
+
+func TestPersistMigrate(t *testing.T) {
+	for _, codec := range []Codec{JSON, TOML, Gob} {
+		path := filepath.Join(t.TempDir(), "settings")
+
+		old := NewAtom(settingsV1{})
+		flush, err := Persist(old, path, PersistOptions[settingsV1]{Codec: codec, Version: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		old.update(func(settingsV1) settingsV1 { return settingsV1{Dark: true} })
+		if err := flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		var migrated int
+		current := NewAtom(settingsV2{Theme: "light", Width: 80})
+		_, err = Persist(current, path, PersistOptions[settingsV2]{
+			Codec:   codec,
+			Version: 2,
+			Migrate: func(version int, decode func(v any) error) (settingsV2, error) {
+				migrated = version
+				var v1 settingsV1
+				if err := decode(&v1); err != nil {
+					return settingsV2{}, err
+				}
+				return settingsV2{Theme: Conditional(v1.Dark, "dark", "light"), Width: 80}, nil
+			},
+		})
+		if err != nil {
+			t.Fatalf("%T: %v", codec, err)
+		}
+		if migrated != 1 {
+			t.Errorf("%T: Migrate called with version %d, want 1", codec, migrated)
+		}
+		if got, want := current.Get(), (settingsV2{Theme: "dark", Width: 80}); got != want {
+			t.Errorf("%T: migrated to %+v, want %+v", codec, got, want)
+		}
+	}
+}
+
+func TestPersistKeepsDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.toml")
+	if err := os.WriteFile(path, []byte("version = 2\n\n[value]\ntheme = \"dark\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	atom := NewAtom(settingsV2{Theme: "light", Width: 80})
+	if _, err := Persist(atom, path, PersistOptions[settingsV2]{Codec: TOML, Version: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := atom.Get(), (settingsV2{Theme: "dark", Width: 80}); got != want {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+}
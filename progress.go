@@ -0,0 +1,171 @@
+package matcha
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// progressBlocks are the partially filled cells used to draw a progress bar
+// with eighth-of-a-cell precision, from empty to full.
+var progressBlocks = []rune{' ', '▏', '▎', '▍', '▌', '▋', '▊', '▉', '█'}
+
+// indeterminatePeriod is the time an indeterminate progress bar takes to
+// sweep back and forth once.
+const indeterminatePeriod = 2 * time.Second
+
+// ProgressBarProps configures a ProgressBar.
+type ProgressBarProps struct {
+	// Value is the completed fraction, between 0 and 1. It is ignored by
+	// indeterminate bars.
+	Value float64
+	// Indeterminate bars show activity without a known completion, as a
+	// segment sweeping back and forth.
+	Indeterminate bool
+	// Width is the number of cells of the bar. Defaults to 20.
+	Width int
+	// From and To, when both set, color the filled part with a gradient
+	// going from the left to the right end of the bar. Otherwise the bar is
+	// drawn with Style's colors.
+	From, To lipgloss.TerminalColor
+	Style    lipgloss.Style
+}
+
+type progressBar struct {
+	props ProgressBarProps
+}
+
+// ProgressBar renders a horizontal progress bar.
+//
+// Indeterminate bars are animated by the framework's frame clock, so they
+// only cause frames while they are mounted.
+func ProgressBar(props ProgressBarProps) Component {
+	return &progressBar{props: props}
+}
+
+func (p *progressBar) Render(ctx *Context) Component {
+	width := p.props.Width
+	if width <= 0 {
+		width = 20
+	}
+
+	started, _ := UseState(ctx, time.Now())
+
+	var cells []rune
+	if p.props.Indeterminate {
+		cells = indeterminateCells(width, max(useFrameTime(ctx).Sub(started), 0))
+	} else {
+		cells = progressCells(width, p.props.Value)
+	}
+
+	if p.props.From == nil || p.props.To == nil {
+		return Text(string(cells), p.props.Style)
+	}
+
+	inline := inlineStyle(p.props.Style)
+	children := make([]Component, 0, width)
+	for i, cell := range cells {
+		t := 0.0
+		if width > 1 {
+			t = float64(i) / float64(width-1)
+		}
+		children = append(children, Text(string(cell), inline.Foreground(lerpColor(p.props.From, p.props.To, t))))
+	}
+	return Row(children, p.props.Style)
+}
+
+// progressCells returns the cells of a bar `width` cells wide filled to
+// `value`, using partial blocks for the last filled cell.
+func progressCells(width int, value float64) []rune {
+	value = min(max(value, 0), 1)
+	eighths := int(math.Round(value * float64(width*8)))
+
+	cells := []rune(strings.Repeat(" ", width))
+	for i := range cells {
+		filled := min(max(eighths-i*8, 0), 8)
+		cells[i] = progressBlocks[filled]
+	}
+	return cells
+}
+
+// indeterminateCells returns the cells of an indeterminate bar `width` cells
+// wide, `elapsed` after it started: a segment of a quarter of the bar moving
+// back and forth.
+func indeterminateCells(width int, elapsed time.Duration) []rune {
+	segment := max(width/4, 1)
+	travel := width - segment
+
+	phase := float64(elapsed%indeterminatePeriod) / float64(indeterminatePeriod)
+	position := 2 * phase
+	if position > 1 {
+		position = 2 - position
+	}
+	start := int(math.Round(position * float64(travel)))
+
+	cells := []rune(strings.Repeat(" ", width))
+	for i := start; i < start+segment && i < width; i++ {
+		cells[i] = '█'
+	}
+	return cells
+}
+
+// SpinnerFrames is a set of frames cycled through by a Spinner.
+type SpinnerFrames struct {
+	Frames   []string
+	Interval time.Duration
+}
+
+// Built-in spinner frame sets.
+var (
+	SpinnerDots   = SpinnerFrames{Frames: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}, Interval: 80 * time.Millisecond}
+	SpinnerLine   = SpinnerFrames{Frames: []string{"|", "/", "-", "\\"}, Interval: 100 * time.Millisecond}
+	SpinnerCircle = SpinnerFrames{Frames: []string{"◐", "◓", "◑", "◒"}, Interval: 120 * time.Millisecond}
+	SpinnerPulse  = SpinnerFrames{Frames: []string{"█", "▓", "▒", "░", "▒", "▓"}, Interval: 120 * time.Millisecond}
+	SpinnerArrows = SpinnerFrames{Frames: []string{"←", "↖", "↑", "↗", "→", "↘", "↓", "↙"}, Interval: 100 * time.Millisecond}
+)
+
+// SpinnerProps configures a Spinner.
+type SpinnerProps struct {
+	// Frames defaults to SpinnerDots.
+	Frames SpinnerFrames
+	// Label is drawn after the spinner, separated by a space.
+	Label string
+	Style lipgloss.Style
+}
+
+type spinner struct {
+	props SpinnerProps
+}
+
+// Spinner renders an animated activity indicator.
+//
+// Spinners are animated by the framework's frame clock rather than their own
+// timers, so any number of them share the same frames and an application
+// without mounted spinners stays idle. A spinner is only rendered again when
+// its glyph changes, not on every frame.
+func Spinner(props SpinnerProps) Component {
+	return &spinner{props: props}
+}
+
+func (s *spinner) Render(ctx *Context) Component {
+	frames := s.props.Frames
+	if len(frames.Frames) == 0 {
+		frames = SpinnerDots
+	}
+	interval := max(frames.Interval, time.Millisecond)
+
+	now := frameTime(ctx)
+	started, _ := UseState(ctx, now)
+	elapsed := max(now.Sub(started), 0)
+	step := elapsed / interval
+	frame := frames.Frames[int(step)%len(frames.Frames)]
+	// Nothing changes until the next step, so no frame is needed before.
+	requestFrameAt(ctx, started.Add((step+1)*interval))
+
+	if s.props.Label == "" {
+		return Text(frame, s.props.Style)
+	}
+	return Text(frame+" "+s.props.Label, s.props.Style)
+}
@@ -0,0 +1,42 @@
+package matcha
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestSpinnerFrames steps the frame clock and checks that a spinner only
+// causes a frame when its glyph changes, rather than one per frame slot.
+func TestSpinnerFrames(t *testing.T) {
+	now := time.Unix(0, 0)
+	app := newTestApp(t, Spinner(SpinnerProps{Frames: SpinnerFrames{Frames: []string{"a", "b", "c"}, Interval: 100 * time.Millisecond}}))
+	app.managers.clock.source = func() time.Time { return now }
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	for _, step := range []struct {
+		advance time.Duration
+		redraw  bool
+		glyph   string
+	}{
+		{50 * time.Millisecond, false, "a"},
+		{50 * time.Millisecond, true, "b"},
+		{99 * time.Millisecond, false, "b"},
+		{time.Millisecond, true, "c"},
+		{150 * time.Millisecond, true, "a"},
+		{10 * time.Millisecond, false, "a"},
+	} {
+		now = now.Add(step.advance)
+		app.scheduler.expire(now)
+		if redraw := app.scheduler.dirty.Load(); redraw != step.redraw {
+			t.Fatalf("at %v: frame requested = %t, want %t", now.Sub(time.Unix(0, 0)), redraw, step.redraw)
+		}
+		if step.redraw {
+			drawFrame(app)
+		}
+		if texts := rendered(app.scene.Load().root); !slices.Equal(texts, []string{step.glyph}) {
+			t.Fatalf("at %v: rendered %q, want %q", now.Sub(time.Unix(0, 0)), texts, step.glyph)
+		}
+	}
+}
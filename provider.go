@@ -0,0 +1,151 @@
+package matcha
+
+import "sync"
+
+// ContextKey identifies a value passed down the tree with Provide. Keys are
+// compared by identity, so each key is typically a package-level variable:
+//
+//	var ThemeKey = NewContextKey("theme", DefaultTheme)
+type ContextKey[T any] struct {
+	name         string
+	defaultValue T
+}
+
+// NewContextKey creates a key for values of type T. `defaultValue` is what
+// UseContextValue returns for components without a provider above them.
+func NewContextKey[T any](name string, defaultValue T) *ContextKey[T] {
+	return &ContextKey[T]{name: name, defaultValue: defaultValue}
+}
+
+// String returns the name of the key.
+func (k *ContextKey[T]) String() string {
+	return k.name
+}
+
+// contextProvider is implemented by the components returned by Provide.
+type contextProvider interface {
+	provided(key any) (any, bool)
+}
+
+// contextManager tracks the components reading the values of providers, so
+// that they can be rendered again when a value changes.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type contextManager struct {
+	consumers map[componentID]map[componentID]struct{} // Provider ID to the IDs of its consumers.
+	mu        sync.Mutex
+}
+
+// newContextManager creates and returns a new, empty contextManager.
+func newContextManager() *contextManager {
+	return &contextManager{
+		consumers: make(map[componentID]map[componentID]struct{}),
+	}
+}
+
+// consume registers a consumer of a provider.
+//
+// Thread-safe.
+func (c *contextManager) consume(provider, consumer componentID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	consumers, ok := c.consumers[provider]
+	if !ok {
+		consumers = make(map[componentID]struct{})
+		c.consumers[provider] = consumers
+	}
+	consumers[consumer] = struct{}{}
+}
+
+// prune drops the providers and consumers that are not in use.
+//
+// Thread-safe.
+func (c *contextManager) prune(inUse func(id componentID) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for provider, consumers := range c.consumers {
+		if !inUse(provider) {
+			delete(c.consumers, provider)
+			continue
+		}
+		for consumer := range consumers {
+			if !inUse(consumer) {
+				delete(consumers, consumer)
+			}
+		}
+	}
+}
+
+// invalidate marks every consumer of a provider to be rendered again in the
+// current frame.
+//
+// Thread-safe.
+func (c *contextManager) invalidate(provider componentID, dirty *invalidation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for consumer := range c.consumers[provider] {
+		dirty.add(consumer)
+	}
+}
+
+type provider[T any] struct {
+	key   *ContextKey[T]
+	value T
+	child Component
+}
+
+// providedValue is the value a provider rendered last.
+type providedValue[T any] struct {
+	value T
+	set   bool
+}
+
+// Provide makes `value` available to every component below `child` through
+// UseContextValue, which is how themes, configuration and services are
+// passed down without globals:
+//
+//	Provide(ThemeKey, theme, &app{})
+//
+// Providers can be nested; components read the value of the nearest one.
+// When the provided value changes, every component that read it is
+// rendered again, including those below memoized components. Overlays such
+// as dialogs are separate trees, so they only see providers placed inside
+// them.
+func Provide[T any](key *ContextKey[T], value T, child Component) Component {
+	return &provider[T]{key: key, value: value, child: child}
+}
+
+func (p *provider[T]) Render(ctx *Context) Component {
+	last := useRef(ctx, providedValue[T]{})
+	if last.set && !equalValues(last.value, p.value) {
+		// Consumers are walked after their provider, so marking them now
+		// renders them in this very frame.
+		ctx.managers.context.invalidate(componentID(ctx.id), ctx.dirty)
+	}
+	*last = providedValue[T]{value: p.value, set: true}
+	return p.child
+}
+
+func (p *provider[T]) provided(key any) (any, bool) {
+	if k, ok := key.(*ContextKey[T]); ok && k == p.key {
+		return p.value, true
+	}
+	return nil, false
+}
+
+// UseContextValue returns the value provided for `key` by the nearest
+// Provide above the component, or the key's default value if there is none.
+// The component is rendered again whenever that value changes.
+func UseContextValue[T any](ctx *Context, key *ContextKey[T]) T {
+	for n := ctx.node.parent; n != nil; n = n.parent {
+		p, ok := n.component.(contextProvider)
+		if !ok {
+			continue
+		}
+		if value, ok := p.provided(key); ok {
+			ctx.managers.context.consume(componentID(n.id), componentID(ctx.id))
+			return value.(T)
+		}
+	}
+	return key.defaultValue
+}
@@ -0,0 +1,35 @@
+package matcha
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var testKey = NewContextKey("test", 0)
+
+type consumer struct{}
+
+func (c *consumer) Render(ctx *Context) Component {
+	UseContextValue(ctx, testKey)
+	return Text("consumer", lipgloss.NewStyle())
+}
+
+// TestUnmountRemovesConsumers checks that a component reading a provided
+// value stops being one of its consumers once unmounted.
+func TestUnmountRemovesConsumers(t *testing.T) {
+	show := true
+	app := newTestApp(t, Provide(testKey, 1, &shown{show: &show, child: &consumer{}}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if got := len(app.managers.context.consumers["root"]); got != 1 {
+		t.Fatalf("provider has %d consumers, want 1", got)
+	}
+
+	show = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if got := len(app.managers.context.consumers["root"]); got != 0 {
+		t.Errorf("provider has %d consumers after unmount, want 0", got)
+	}
+}
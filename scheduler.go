@@ -0,0 +1,340 @@
+package matcha
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultMaxFPS is the frame rate cap used unless App.SetMaxFPS is called.
+const defaultMaxFPS = 60
+
+// FrameStats reports how the frame scheduler has been pacing frames since
+// the application started.
+type FrameStats struct {
+	// Frames is the number of frames drawn.
+	Frames uint64
+	// Requests is the number of render requests received.
+	Requests uint64
+	// Coalesced is the number of render requests merged into a frame that
+	// was already pending.
+	Coalesced uint64
+	// Dropped is the number of frame slots missed because drawing a frame
+	// took longer than the frame interval.
+	Dropped uint64
+	// LastFrame is the time it took to walk, lay out and draw the last frame.
+	LastFrame time.Duration
+}
+
+// scheduler decides when the build loop draws a frame.
+//
+// Render requests only set a dirty flag and, if it was clear, wake the build
+// loop up without blocking, so they can be made from any goroutine, including
+// event handlers and atom subscribers running on the dispatch goroutine. Any
+// number of requests arriving between two frames are coalesced into a single
+// frame, and frames are never drawn closer together than the frame interval.
+// The first frame is drawn as soon as it is requested. Components may also
+// ask to be rendered again at a given time, such as a spinner's next glyph.
+// While nothing is pending and no such time is set, the scheduler has no
+// timer armed, so an idle application does not wake up at all.
+//
+// The pacing state is only touched by the build loop; the statistics are
+// synchronized with a mutex so they can be read from any goroutine.
+type scheduler struct {
+	interval time.Duration
+	dirty    atomic.Bool   // Whether a frame has been requested but not started yet.
+	wake     chan struct{} // Signalled when the dirty flag gets set.
+	last     time.Time     // When the last frame started; zero before the first one.
+	timer    *time.Timer   // Armed only while a frame waits for its slot or a deadline is set.
+	armed    bool
+
+	invalidated *invalidation             // Components to render again in the next frame.
+	deadlines   map[componentID]time.Time // Components to render again once a time has come.
+	posted      []func()                  // Callbacks to run on the build loop before the next frame.
+	stats       FrameStats
+	mu          sync.Mutex
+}
+
+// newScheduler creates a scheduler drawing at most `fps` frames per second.
+func newScheduler(fps int) *scheduler {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	return &scheduler{
+		interval: frameInterval(fps),
+		wake:     make(chan struct{}, 1),
+		timer:    timer,
+
+		invalidated: newInvalidation(),
+		deadlines:   make(map[componentID]time.Time),
+	}
+}
+
+// frameInterval returns the minimum time between two frames at `fps`.
+func frameInterval(fps int) time.Duration {
+	return time.Second / time.Duration(max(fps, 1))
+}
+
+// invalidate requests a frame rendering the components with the given IDs
+// again, or the whole tree if no ID is given. It never blocks.
+//
+// Thread-safe.
+func (s *scheduler) invalidate(ids ...componentID) {
+	s.mu.Lock()
+	if len(ids) == 0 {
+		s.invalidated.all = true
+	}
+	for _, id := range ids {
+		s.invalidated.add(id)
+	}
+	first := s.dirty.CompareAndSwap(false, true)
+	s.stats.Requests++
+	if !first {
+		s.stats.Coalesced++
+	}
+	s.mu.Unlock()
+
+	if first && !deferWake(s) {
+		s.wakeUp()
+	}
+}
+
+// invalidateAt requests a frame rendering the component again once `at` has
+// come. Only the earliest time requested for a component is kept until it
+// is rendered again. It never blocks.
+//
+// Thread-safe.
+func (s *scheduler) invalidateAt(id componentID, at time.Time) {
+	s.mu.Lock()
+	if current, ok := s.deadlines[id]; !ok || at.Before(current) {
+		s.deadlines[id] = at
+	}
+	s.mu.Unlock()
+
+	// Let the build loop arm its timer for the new deadline.
+	s.wakeUp()
+}
+
+// expire invalidates the components whose deadline has come, and returns
+// the earliest deadline still to come, or the zero time if there is none.
+//
+// Thread-safe.
+func (s *scheduler) expire(now time.Time) (next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, at := range s.deadlines {
+		if at.After(now) {
+			if next.IsZero() || at.Before(next) {
+				next = at
+			}
+			continue
+		}
+		delete(s.deadlines, id)
+		s.invalidated.add(id)
+		s.stats.Requests++
+		if !s.dirty.CompareAndSwap(false, true) {
+			s.stats.Coalesced++
+		}
+	}
+	return next
+}
+
+// wakeUp signals the build loop without blocking.
+//
+// Thread-safe.
+func (s *scheduler) wakeUp() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// post queues `fn` to run on the build loop before the next frame is walked.
+// It does not request the frame itself.
+//
+// Thread-safe.
+func (s *scheduler) post(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.posted = append(s.posted, fn)
+}
+
+// take returns the components invalidated since the last call, for the frame
+// about to be drawn.
+//
+// Thread-safe.
+func (s *scheduler) take() *invalidation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	invalidated := s.invalidated
+	s.invalidated = newInvalidation()
+	return invalidated
+}
+
+// due reports whether the pending frame, if any, can be drawn now. If it has
+// to wait for its slot, the timer is armed to fire when it opens; if there
+// is none, it is armed for the earliest deadline, if any.
+func (s *scheduler) due() bool {
+	now := time.Now()
+	next := s.expire(now)
+	if !s.dirty.Load() {
+		if !next.IsZero() {
+			s.arm(next.Sub(now))
+		}
+		return false
+	}
+	wait := s.interval - now.Sub(s.last)
+	if s.last.IsZero() || wait <= 0 {
+		return true
+	}
+	s.arm(wait)
+	return false
+}
+
+// arm sets the timer to fire after `wait`, replacing any earlier setting.
+func (s *scheduler) arm(wait time.Duration) {
+	s.disarm()
+	s.timer.Reset(wait)
+	s.armed = true
+}
+
+// disarm stops the timer if it is armed.
+func (s *scheduler) disarm() {
+	if s.armed && !s.timer.Stop() {
+		<-s.timer.C
+	}
+	s.armed = false
+}
+
+// fired must be called when the timer fires. It reports whether the pending
+// frame is due.
+func (s *scheduler) fired() bool {
+	s.armed = false
+	return s.due()
+}
+
+// draw runs the posted callbacks, then `frame`, and records its timing.
+// Requests made while drawing, including those of animated components
+// asking for their next frame, are drawn in the next slot, while those made
+// by the posted callbacks are drawn in this one.
+func (s *scheduler) draw(frame func()) {
+	s.disarm()
+
+	s.mu.Lock()
+	posted := s.posted
+	s.posted = nil
+	s.mu.Unlock()
+	for _, fn := range posted {
+		fn()
+	}
+
+	// Cleared before walking, so that requests made during the frame cause
+	// another one.
+	s.dirty.Store(false)
+
+	start := time.Now()
+	s.last = start
+	frame()
+	elapsed := time.Since(start)
+
+	s.mu.Lock()
+	s.stats.Frames++
+	s.stats.LastFrame = elapsed
+	if elapsed > s.interval {
+		s.stats.Dropped += uint64(elapsed / s.interval)
+	}
+	s.mu.Unlock()
+}
+
+// snapshot returns a copy of the statistics.
+//
+// Thread-safe.
+func (s *scheduler) snapshot() FrameStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// SetMaxFPS caps the number of frames drawn per second. Render requests made
+// between two frames are coalesced into one. Must be called before Render.
+func (a *App) SetMaxFPS(fps int) {
+	a.scheduler.interval = frameInterval(fps)
+}
+
+// RequestRender asks for a frame rendering the whole tree again, e.g. after
+// changing global state the framework does not track. Requests are
+// coalesced, so it is cheap to call repeatedly.
+//
+// It never blocks and may be called from any goroutine, including from event
+// handlers and atom subscribers.
+func (a *App) RequestRender() {
+	a.scheduler.invalidate()
+}
+
+// RequestRender asks for a frame rendering the component associated with
+// this Context again, along with its subtree. Like App.RequestRender, it
+// never blocks and may be called from any goroutine.
+func (c *Context) RequestRender() {
+	c.scheduler.invalidate(componentID(c.id))
+}
+
+// post runs `fn` on the build loop before the next frame, which renders the
+// component associated with this Context again. It is how results of
+// background work are delivered without racing the walk of the tree.
+func (c *Context) post(fn func()) {
+	c.scheduler.post(fn)
+	c.scheduler.invalidate(componentID(c.id))
+}
+
+// FrameStats returns statistics about the frames drawn so far.
+//
+// Thread-safe.
+func (a *App) FrameStats() FrameStats {
+	return a.scheduler.snapshot()
+}
+
+// invalidation is the set of components to render again in a frame.
+//
+// Component IDs are paths from the root, so the ancestors of an invalidated
+// component are tracked too: walking has to go through them to reach it,
+// but does not need to render them.
+type invalidation struct {
+	all      bool // Whether the whole tree is rendered again.
+	marked   map[componentID]struct{}
+	ancestry map[componentID]struct{} // Marked components and their ancestors.
+}
+
+// newInvalidation creates and returns an empty invalidation.
+func newInvalidation() *invalidation {
+	return &invalidation{
+		marked:   make(map[componentID]struct{}),
+		ancestry: make(map[componentID]struct{}),
+	}
+}
+
+// add marks a component.
+func (v *invalidation) add(id componentID) {
+	v.marked[id] = struct{}{}
+	path := string(id)
+	for {
+		v.ancestry[componentID(path)] = struct{}{}
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			return
+		}
+		path = path[:i]
+	}
+}
+
+// marks reports whether the component must be rendered again.
+func (v *invalidation) marks(id string) bool {
+	_, ok := v.marked[componentID(id)]
+	return v.all || ok
+}
+
+// reaches reports whether the component or one of its descendants must be
+// rendered again.
+func (v *invalidation) reaches(id string) bool {
+	_, ok := v.ancestry[componentID(id)]
+	return v.all || ok
+}
@@ -0,0 +1,353 @@
+package matcha
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Option is a labelled choice of a RadioGroup or Select.
+type Option[T comparable] struct {
+	Label string
+	Value T
+}
+
+// ToggleProps configures a Checkbox or a Switch.
+type ToggleProps struct {
+	// ID is the focusable ID registered through UseFocus.
+	ID    string
+	Label string
+	// Value holds the state of the control. Every control bound to the same
+	// atom stays in sync.
+	Value        *Atom[bool]
+	Disabled     bool
+	Style        lipgloss.Style
+	FocusedStyle lipgloss.Style
+}
+
+type toggle struct {
+	props  ToggleProps
+	glyphs func(checked bool) string
+}
+
+// Checkbox renders a checkbox bound to a boolean atom. It is toggled with
+// Space or Enter while focused, or with a click.
+func Checkbox(props ToggleProps) Component {
+	return &toggle{props: props, glyphs: checkGlyph}
+}
+
+// Switch renders an on/off switch bound to a boolean atom. It is toggled
+// with Space or Enter while focused, or with a click.
+func Switch(props ToggleProps) Component {
+	return &toggle{props: props, glyphs: switchGlyph}
+}
+
+func (t *toggle) Render(ctx *Context) Component {
+	checked, setChecked := UseAtomState(ctx, t.props.Value)
+	isFocused, setFocus, _ := UseFocus(ctx, t.props.ID)
+
+	flip := func() {
+		if !t.props.Disabled {
+			setChecked(func(checked bool) bool { return !checked })
+		}
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if isPrimaryClick(event) {
+			setFocus(t.props.ID)
+			flip()
+			return true
+		}
+		if key, ok := event.(*tcell.EventKey); ok && isActivationKey(key) {
+			flip()
+			return true
+		}
+		return false
+	})
+
+	style := t.props.Style
+	if isFocused {
+		style = t.props.FocusedStyle
+	}
+	if t.props.Disabled {
+		style = style.Faint(true)
+	}
+	return Text(t.glyphs(checked)+" "+t.props.Label, style)
+}
+
+// RadioGroupProps configures a RadioGroup.
+type RadioGroupProps[T comparable] struct {
+	// ID is the focusable ID registered through UseFocus.
+	ID      string
+	Options []Option[T]
+	// Value holds the selected option. Every control bound to the same atom
+	// stays in sync.
+	Value *Atom[T]
+	// Horizontal lays the options out in a row instead of a column.
+	Horizontal   bool
+	Style        lipgloss.Style
+	FocusedStyle lipgloss.Style
+}
+
+type radioGroup[T comparable] struct {
+	props RadioGroupProps[T]
+}
+
+// RadioGroup renders a list of mutually exclusive options bound to an atom.
+// The arrow keys move the selection while focused, and options can be
+// clicked.
+func RadioGroup[T comparable](props RadioGroupProps[T]) Component {
+	return &radioGroup[T]{props: props}
+}
+
+func (r *radioGroup[T]) Render(ctx *Context) Component {
+	value, setValue := UseAtomState(ctx, r.props.Value)
+	isFocused, setFocus, _ := UseFocus(ctx, r.props.ID)
+	options := r.props.Options
+
+	selected := slices.IndexFunc(options, func(o Option[T]) bool { return o.Value == value })
+	choose := func(index int) {
+		if index >= 0 && index < len(options) {
+			setValue(func(T) T { return options[index].Value })
+		}
+	}
+	// step moves the selection from the current value rather than the
+	// rendered one, as several keys may be handled before the next frame.
+	step := func(offset int) {
+		setValue(func(value T) T {
+			current := slices.IndexFunc(options, func(o Option[T]) bool { return o.Value == value })
+			if current < 0 && offset < 0 {
+				current = 0
+			}
+			return options[(current+offset+len(options))%len(options)].Value
+		})
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok || len(options) == 0 {
+			return false
+		}
+		switch key.Key() {
+		case tcell.KeyDown, tcell.KeyRight:
+			step(1)
+		case tcell.KeyUp, tcell.KeyLeft:
+			step(-1)
+		default:
+			return false
+		}
+		return true
+	})
+
+	style := r.props.Style
+	if isFocused {
+		style = r.props.FocusedStyle
+	}
+	children := make([]Component, 0, len(options))
+	for i, option := range options {
+		children = append(children, &clickable{
+			child: Text(radioGlyph(i == selected)+" "+option.Label, style),
+			onClick: func() {
+				setFocus(r.props.ID)
+				choose(i)
+			},
+		})
+	}
+	if r.props.Horizontal {
+		return Row(children, lipgloss.NewStyle())
+	}
+	return Column(children, lipgloss.NewStyle())
+}
+
+// SelectProps configures a Select.
+type SelectProps[T comparable] struct {
+	// ID is the focusable ID registered through UseFocus.
+	ID      string
+	Options []Option[T]
+	// Value holds the selected option. Every control bound to the same atom
+	// stays in sync.
+	Value *Atom[T]
+	// Placeholder is shown when the value matches none of the options.
+	Placeholder  string
+	Style        lipgloss.Style
+	FocusedStyle lipgloss.Style
+}
+
+type selectBox[T comparable] struct {
+	props SelectProps[T]
+}
+
+// Select renders the label of the selected option and opens a dropdown with
+// all options on Enter, Space, Down or a click.
+//
+// The dropdown is drawn below the select as an overlay. Typing filters the
+// options, Up/Down move through them, Enter picks one and Escape or a click
+// outside closes the dropdown without changing the value.
+func Select[T comparable](props SelectProps[T]) Component {
+	return &selectBox[T]{props: props}
+}
+
+func (s *selectBox[T]) Render(ctx *Context) Component {
+	value, setValue := UseAtomState(ctx, s.props.Value)
+	isFocused, setFocus, _ := UseFocus(ctx, s.props.ID)
+	overlays := ctx.managers.overlay
+
+	label := s.props.Placeholder
+	if index := slices.IndexFunc(s.props.Options, func(o Option[T]) bool { return o.Value == value }); index >= 0 {
+		label = s.props.Options[index].Label
+	}
+
+	open := func() {
+		d := &selectDropdown[T]{options: s.props.Options, value: value}
+		var id string
+		d.close = func() {
+			if overlays.remove(id) {
+				ctx.RequestRender()
+			}
+		}
+		d.choose = func(v T) {
+			d.close()
+			setValue(func(T) T { return v })
+		}
+		id = overlays.push(d, overlayOptions{anchor: ctx.id, modal: true, onOutsideClick: d.close})
+		ctx.RequestRender()
+	}
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if isPrimaryClick(event) {
+			setFocus(s.props.ID)
+			open()
+			return true
+		}
+		key, ok := event.(*tcell.EventKey)
+		if ok && (isActivationKey(key) || key.Key() == tcell.KeyDown) {
+			open()
+			return true
+		}
+		return false
+	})
+
+	style := s.props.Style
+	if isFocused {
+		style = s.props.FocusedStyle
+	}
+	return Text(label+" ▾", style)
+}
+
+// selectDropdownState is the local state of a Select's dropdown.
+type selectDropdownState struct {
+	filter textValue
+	cursor int
+}
+
+// selectDropdown is the overlay listing the options of a Select.
+type selectDropdown[T comparable] struct {
+	options []Option[T]
+	value   T
+	close   func()
+	choose  func(value T)
+}
+
+func (d *selectDropdown[T]) Render(ctx *Context) Component {
+	initial := slices.IndexFunc(d.options, func(o Option[T]) bool { return o.Value == d.value })
+	state, setState, getState := useState(ctx, selectDropdownState{cursor: max(initial, 0)})
+
+	// matching returns the options listed for a state and the index of the
+	// one under the cursor.
+	matching := func(s selectDropdownState) ([]Option[T], int) {
+		query := strings.ToLower(s.filter.String())
+		var visible []Option[T]
+		for _, option := range d.options {
+			if strings.Contains(strings.ToLower(option.Label), query) {
+				visible = append(visible, option)
+			}
+		}
+		return visible, min(s.cursor, max(len(visible)-1, 0))
+	}
+	visible, cursor := matching(state)
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok {
+			return false
+		}
+		// Several keys may be handled before the next frame, so each one
+		// works from the latest state rather than the rendered one.
+		switch key.Key() {
+		case tcell.KeyEscape:
+			d.close()
+		case tcell.KeyEnter:
+			if visible, cursor := matching(getState()); cursor < len(visible) {
+				d.choose(visible[cursor].Value)
+			}
+		case tcell.KeyUp:
+			setState(func(s selectDropdownState) selectDropdownState {
+				_, cursor := matching(s)
+				s.cursor = max(cursor-1, 0)
+				return s
+			})
+		case tcell.KeyDown:
+			setState(func(s selectDropdownState) selectDropdownState {
+				visible, cursor := matching(s)
+				s.cursor = min(cursor+1, max(len(visible)-1, 0))
+				return s
+			})
+		default:
+			if !isEditKey(key) {
+				return false
+			}
+			setState(func(s selectDropdownState) selectDropdownState {
+				s.filter, _ = s.filter.edit(key)
+				s.cursor = 0
+				return s
+			})
+		}
+		return true
+	})
+
+	children := []Component{renderTextValue(state.filter, "Filter…", true, lipgloss.NewStyle().Faint(true))}
+	for i, option := range visible {
+		children = append(children, &clickable{
+			child:   Text(option.Label, lipgloss.NewStyle().Reverse(i == cursor)),
+			onClick: func() { d.choose(option.Value) },
+		})
+	}
+	if len(visible) == 0 {
+		children = append(children, Text("No matches", lipgloss.NewStyle().Faint(true)))
+	}
+
+	return Column(children, lipgloss.NewStyle().Border(lipgloss.NormalBorder()))
+}
+
+// clickable calls onClick when its child is clicked.
+type clickable struct {
+	child   Component
+	onClick func()
+}
+
+func (c *clickable) Render(ctx *Context) Component {
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if !isPrimaryClick(event) {
+			return false
+		}
+		c.onClick()
+		return true
+	})
+	return c.child
+}
+
+// isActivationKey reports whether the key activates a focused control:
+// Enter or Space.
+func isActivationKey(key *tcell.EventKey) bool {
+	return key.Key() == tcell.KeyEnter || key.Key() == tcell.KeyRune && key.Rune() == ' '
+}
+
+// switchGlyph returns the glyph of a switch.
+func switchGlyph(on bool) string {
+	if on {
+		return "━●"
+	}
+	return "○━"
+}
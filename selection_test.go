@@ -0,0 +1,44 @@
+package matcha
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// TestSelectKeysBeforeFrame filters and moves through a Select's dropdown
+// faster than it renders.
+func TestSelectKeysBeforeFrame(t *testing.T) {
+	fruit := NewAtom("apple")
+	var options []Option[string]
+	for _, name := range []string{"apple", "banana", "blueberry", "cherry"} {
+		options = append(options, Option[string]{Label: name, Value: name})
+	}
+	app := newTestApp(t, Select(SelectProps[string]{ID: "fruit", Options: options, Value: fruit}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	press(t, app, "root", tcell.KeyEnter, 0)
+	drawFrame(app)
+	press(t, app, "overlay/1", tcell.KeyRune, 'e')
+	press(t, app, "overlay/1", tcell.KeyDown, 0)
+	press(t, app, "overlay/1", tcell.KeyDown, 0)
+	press(t, app, "overlay/1", tcell.KeyEnter, 0)
+	if got := fruit.Get(); got != "cherry" {
+		t.Errorf("chose %q, want %q", got, "cherry")
+	}
+}
+
+func TestRadioGroupKeysBeforeFrame(t *testing.T) {
+	size := NewAtom(0)
+	options := []Option[int]{{Label: "S", Value: 0}, {Label: "M", Value: 1}, {Label: "L", Value: 2}}
+	app := newTestApp(t, RadioGroup(RadioGroupProps[int]{ID: "size", Options: options, Value: size}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	press(t, app, "root", tcell.KeyDown, 0)
+	press(t, app, "root", tcell.KeyDown, 0)
+	if got := size.Get(); got != 2 {
+		t.Errorf("selected %d, want 2", got)
+	}
+}
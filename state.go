@@ -3,6 +3,7 @@ package matcha
 import (
 	"fmt"
 	gomaps "maps"
+	"reflect"
 	"sync"
 )
 
@@ -26,12 +27,47 @@ type Subscriber[T any] struct {
 //     ensure stale subscribers from previous UI builds are never removed prematurely.
 //
 // Subscribers are stored in a map keyed by subscriber ID.
+//
+// Atoms are best created with NewAtom, which lets updates that do not
+// change the value skip notifications. Atoms created with Computed or
+// Selector derive their value from other atoms instead of holding a value of
+// their own; see Computed.
 type Atom[T any] struct {
 	ID          string
 	Value       T
 	subscribers map[string]*Subscriber[T]
+	watchers    []func() // Persistent callbacks of the computed atoms deriving from this one.
 	mu          sync.RWMutex
 	version     int // Incremented on every update; used to isolate subscriptions between renders.
+
+	derive func() T          // Computes the value of a computed atom; nil for plain atoms.
+	equal  func(a, b T) bool // Tells whether an update changed the value; nil means it always did.
+	stale  bool              // Whether a dependency of a computed atom changed since it was computed.
+}
+
+// NewAtom creates an atom holding `value`.
+//
+// Updates leaving the value unchanged, as reported by `equal`, neither
+// notify subscribers nor cause rerenders. Without `equal`, values are
+// compared with ==, and updates of types that are not comparable, such as
+// slices and maps, always notify.
+//
+//	count := NewAtom(0)
+//	items := NewAtom([]string{}, slices.Equal[[]string])
+func NewAtom[T any](value T, equal ...func(a, b T) bool) *Atom[T] {
+	return &Atom[T]{Value: value, equal: equalFunc(equal)}
+}
+
+// equalFunc returns the equality function passed as an optional argument,
+// or the default one: == for comparable types, nil otherwise.
+func equalFunc[T any](equal []func(a, b T) bool) func(a, b T) bool {
+	if len(equal) > 0 && equal[0] != nil {
+		return equal[0]
+	}
+	if !reflect.TypeFor[T]().Comparable() {
+		return nil
+	}
+	return func(a, b T) bool { return equalValues(a, b) }
 }
 
 // subscribe adds a new subscriber to the Atom.
@@ -69,7 +105,8 @@ func (a *Atom[T]) unsubscribe(subscribers map[string]*Subscriber[T]) {
 	}
 }
 
-// value returns the current value stored in the Atom.
+// value returns the current value stored in the Atom, computing it first if
+// the Atom is a stale computed atom.
 //
 // This method acquires a read lock to ensure safe concurrent access,
 // making it safe to call from multiple goroutines without risking
@@ -78,37 +115,102 @@ func (a *Atom[T]) unsubscribe(subscribers map[string]*Subscriber[T]) {
 // The returned value is a snapshot at the moment of the call;
 // it is not guaranteed to reflect future changes.
 func (a *Atom[T]) value() T {
+	if a.derive != nil {
+		a.refresh()
+	}
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 	return a.Value
 }
 
+// Get returns the current value of the Atom. Computed atoms are computed
+// first if one of their dependencies changed.
+//
+// Thread-safe.
+func (a *Atom[T]) Get() T {
+	return a.value()
+}
+
 // update atomically updates the Atom's value and notifies all subscribers.
 //
 // The provided `updateFn` receives the current value and must return the new value.
 //
 // Implementation details:
-//  1. The subscriber map is copied inside the lock. This prevents:
-//     - Holding the lock while running callbacks (avoids deadlocks).
-//     - Panics from concurrent map iteration/writes.
-//     - Inconsistent subscriber sets if subscribe/unsubscribe happens mid-notification.
-//  2. The `version` is incremented with every update. Subscriber IDs embed this version so
-//     that only subscribers from the same render cycle are cleaned up.
-//  3. Callbacks are invoked outside the lock and wrapped with `recover` so that a panic in
-//     one subscriber does not prevent others from running or crash the system.
-//  4. After notifying, stale subscribers from the old version are batch-removed.
+//  1. Updates that leave the value unchanged according to the Atom's equality
+//     function are dropped: nothing is notified and the version is kept.
+//  2. The `version` is incremented with every other update. Subscriber IDs embed this
+//     version so that only subscribers from the same render cycle are cleaned up.
+//  3. Subscribers are then notified through notify, at the end of the
+//     current Batch if there is one.
 //
 // Thread-safe.
 func (a *Atom[T]) update(updateFn func(oldValue T) T) {
-	var newValue T
-	subscribers := make(map[string]*Subscriber[T], len(a.subscribers))
+	a.updateVersion(updateFn)
+}
 
+// updateVersion is update, returning the version of the new value, or 0 if
+// the update was dropped.
+//
+// Thread-safe.
+func (a *Atom[T]) updateVersion(updateFn func(oldValue T) T) (version int) {
+	newValue, version := a.swap(updateFn)
+	if version == 0 {
+		return 0
+	}
+	if deferNotify(a, func() { a.notify(a.value()) }) {
+		return version
+	}
+	a.notify(newValue)
+	return version
+}
+
+// swap replaces the value with the result of `updateFn`, unless the Atom's
+// equality function reports it unchanged, and returns the new version, or 0
+// if the value was kept. updateFn and the equality function run under the
+// lock, so that concurrent updates don't interleave, and a panic in either
+// leaves the Atom unlocked and unchanged.
+func (a *Atom[T]) swap(updateFn func(oldValue T) T) (newValue T, version int) {
 	a.mu.Lock()
+	defer a.mu.Unlock()
 	oldValue := a.Value
 	newValue = updateFn(oldValue)
+	if a.equal != nil && a.equal(oldValue, newValue) {
+		return newValue, 0
+	}
 	a.Value = newValue
-	gomaps.Copy(subscribers, a.subscribers)
 	a.version++
+	return newValue, a.version
+}
+
+// snapshot returns the current value of a non-computed Atom along with its
+// version.
+//
+// Thread-safe.
+func (a *Atom[T]) snapshot() (T, int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.Value, a.version
+}
+
+// notify calls every subscriber with the new value, then the watchers of the
+// computed atoms deriving from this one.
+//
+// Implementation details:
+//  1. The subscriber map is copied inside the lock. This prevents:
+//     - Holding the lock while running callbacks (avoids deadlocks).
+//     - Panics from concurrent map iteration/writes.
+//     - Inconsistent subscriber sets if subscribe/unsubscribe happens mid-notification.
+//  2. Callbacks are invoked outside the lock and wrapped with `recover` so that a panic in
+//     one subscriber does not prevent others from running or crash the system.
+//  3. After notifying, stale subscribers from the old version are batch-removed.
+//     Watchers are persistent and never removed.
+//
+// Thread-safe.
+func (a *Atom[T]) notify(newValue T) {
+	a.mu.Lock()
+	subscribers := make(map[string]*Subscriber[T], len(a.subscribers))
+	gomaps.Copy(subscribers, a.subscribers)
+	watchers := a.watchers
 	a.mu.Unlock()
 
 	for _, subscriber := range subscribers {
@@ -123,6 +225,103 @@ func (a *Atom[T]) update(updateFn func(oldValue T) T) {
 	}
 
 	a.unsubscribe(subscribers)
+
+	for _, watcher := range watchers {
+		watcher()
+	}
+}
+
+// watch registers a callback called after every change of the Atom's value,
+// for as long as the Atom lives. It implements Dependency.
+//
+// Thread-safe.
+func (a *Atom[T]) watch(fn func()) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.watchers = append(a.watchers, fn)
+}
+
+// Dependency is an atom a computed atom derives from. Every *Atom[T],
+// computed or not, is a Dependency.
+type Dependency interface {
+	watch(fn func())
+}
+
+// Computed creates a read-only atom whose value is derived by `derive` from
+// the given dependencies, typically by reading them with Get:
+//
+//	total := Computed(func() int { return price.Get() * quantity.Get() }, nil, price, quantity)
+//
+// The value is computed lazily: a change of a dependency only marks the
+// atom as stale, and it is computed again the next time it is read. While
+// the atom is observed, by a component through UseAtomValue or by another
+// computed atom, it is computed again as soon as a dependency changes, and
+// its subscribers are only notified if `equal` reports that the derived
+// value changed. A nil `equal` defaults to == as in NewAtom.
+//
+// Computed atoms keep a persistent subscription on their dependencies, so
+// they are meant to be created once, alongside the atoms they derive from,
+// rather than during a render. They must not be updated directly.
+func Computed[T any](derive func() T, equal func(a, b T) bool, deps ...Dependency) *Atom[T] {
+	atom := &Atom[T]{derive: derive, equal: equalFunc([]func(a, b T) bool{equal}), stale: true}
+	for _, dep := range deps {
+		dep.watch(atom.invalidate)
+	}
+	return atom
+}
+
+// Selector creates a computed atom holding the part of `source` picked by
+// `selector`. Components reading the selector are only rendered again when
+// the selected part changes, as reported by `equal` (== when nil):
+//
+//	name := Selector(user, func(u User) string { return u.Name }, nil)
+//
+// See Computed.
+func Selector[S, T any](source *Atom[S], selector func(S) T, equal func(a, b T) bool) *Atom[T] {
+	return Computed(func() T { return selector(source.Get()) }, equal, source)
+}
+
+// invalidate marks a computed atom as stale after a dependency changed, and
+// recomputes it right away if it is observed, notifying its subscribers if
+// its value changed.
+//
+// Thread-safe.
+func (a *Atom[T]) invalidate() {
+	a.mu.Lock()
+	a.stale = true
+	observed := len(a.subscribers) > 0 || len(a.watchers) > 0
+	a.mu.Unlock()
+
+	if observed && a.refresh() && !deferNotify(a, func() { a.notify(a.value()) }) {
+		a.notify(a.value())
+	}
+}
+
+// refresh computes a stale computed atom again and reports whether its value
+// changed. The value is derived outside the lock, as deriving reads other
+// atoms.
+//
+// Thread-safe.
+func (a *Atom[T]) refresh() bool {
+	a.mu.Lock()
+	if !a.stale {
+		a.mu.Unlock()
+		return false
+	}
+	a.stale = false
+	oldValue, hasValue := a.Value, a.version > 0
+	a.mu.Unlock()
+
+	newValue := a.derive()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if hasValue && a.equal != nil && a.equal(oldValue, newValue) {
+		return false
+	}
+	a.Value = newValue
+	a.version++
+	return true
 }
 
 // UseAtomState binds an Atom's value to a component's context and returns:
@@ -139,11 +338,9 @@ func (a *Atom[T]) update(updateFn func(oldValue T) T) {
 func UseAtomState[T any](ctx *Context, atom *Atom[T]) (T, func(func(T) T)) {
 	id := fmt.Sprintf("%s/%d", ctx.id, atom.version)
 	atom.subscribe(&Subscriber[T]{id: id, cb: func(value T) {
-		ctx.channels.render <- struct{}{}
+		ctx.RequestRender()
 	}})
-	atom.mu.RLock()
-	defer atom.mu.RUnlock()
-	return atom.Value, atom.update
+	return atom.value(), atom.update
 }
 
 // UseAtomValue binds an Atom's value to a component's context and returns
@@ -154,11 +351,9 @@ func UseAtomState[T any](ctx *Context, atom *Atom[T]) (T, func(func(T) T)) {
 func UseAtomValue[T any](ctx *Context, atom *Atom[T]) T {
 	id := fmt.Sprintf("%s/%d", ctx.id, atom.version)
 	atom.subscribe(&Subscriber[T]{id: id, cb: func(value T) {
-		ctx.channels.render <- struct{}{}
+		ctx.RequestRender()
 	}})
-	atom.mu.RLock()
-	defer atom.mu.RUnlock()
-	return atom.Value
+	return atom.value()
 }
 
 // UseAtomSetter binds only a setter function for updating the Atom's value.
@@ -168,7 +363,156 @@ func UseAtomValue[T any](ctx *Context, atom *Atom[T]) T {
 func UseAtomSetter[T any](ctx *Context, atom *Atom[T]) func(func(T) T) {
 	id := fmt.Sprintf("%s/%d", ctx.id, atom.version)
 	atom.subscribe(&Subscriber[T]{id: id, cb: func(value T) {
-		ctx.channels.render <- struct{}{}
+		ctx.RequestRender()
 	}})
 	return atom.update
 }
+
+// stateManager stores component-local state across rerenders.
+//
+// Each component owns an ordered list of slots, keyed by its componentID.
+// Slot-based hooks such as UseState claim the next slot every time they are
+// called, so they must be called in the same order on every render.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type stateManager struct {
+	slots map[componentID][]any
+	mu    sync.Mutex
+}
+
+// newStateManager creates and returns a new, empty stateManager.
+func newStateManager() *stateManager {
+	return &stateManager{
+		slots: make(map[componentID][]any),
+	}
+}
+
+// stateSlot returns the value stored in the component's slot at `index`,
+// initializing it with `initial()` if the slot does not exist yet.
+//
+// A slot holding a value of another type belongs to a different component
+// that had the same ID, such as the other branch of a Conditional. The slot
+// and the ones after it are then initialized again rather than reused.
+//
+// Thread-safe.
+func stateSlot[T any](s *stateManager, id componentID, index int, initial func() T) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	slots := s.slots[id]
+	if index < len(slots) {
+		if value, ok := slots[index].(T); ok {
+			return value
+		}
+		slots = slots[:index]
+	}
+	// Hooks run in order, so a missing slot is always the next one.
+	value := initial()
+	s.slots[id] = append(slots, value)
+	return value
+}
+
+// prune drops the slots of the components that are not in use.
+//
+// Thread-safe.
+func (s *stateManager) prune(inUse func(id componentID) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.slots {
+		if !inUse(id) {
+			delete(s.slots, id)
+		}
+	}
+}
+
+// nextHook claims the next hook slot of the component being rendered.
+func (c *Context) nextHook() int {
+	index := c.hooks
+	c.hooks++
+	return index
+}
+
+// UseState binds a piece of local state to the component associated with
+// this Context and returns:
+//  1. The current value
+//  2. A setter function for updating the value
+//
+// The state is initialized with `initial` on the first render and kept
+// across rerenders for as long as the component keeps its ID, which is why
+// keyed children (see HasKey) retain their state when siblings move. It is
+// dropped once the component is unmounted, so a component that comes back
+// starts over from `initial`.
+//
+// Like all slot-based hooks, UseState must be called unconditionally and in
+// the same order on every render.
+//
+// The setter has the same shape as the one returned by UseAtomState and
+// triggers a rerender after the update.
+// Thread-safe.
+func UseState[T any](ctx *Context, initial T) (T, func(func(T) T)) {
+	value, setState, _ := useState(ctx, initial)
+	return value, setState
+}
+
+// useState implements UseState, and also returns a function reading the
+// latest value of the state. Several events may be handled before the next
+// frame, so event handlers that need the state as left by the previous
+// events, rather than as it was rendered, read it through that function.
+func useState[T any](ctx *Context, initial T) (T, func(func(T) T), func() T) {
+	manager := ctx.managers.state
+	id, index := componentID(ctx.id), ctx.nextHook()
+
+	value := stateSlot(manager, id, index, func() T { return initial })
+
+	// update applies an updater under the lock, released even if the
+	// updater panics, and reports whether the slot was still this state.
+	update := func(updateFn func(T) T) bool {
+		manager.mu.Lock()
+		defer manager.mu.Unlock()
+		slots := manager.slots[id]
+		if index >= len(slots) {
+			return false
+		}
+		current, ok := slots[index].(T)
+		if !ok {
+			// The slot was taken over by another component.
+			return false
+		}
+		slots[index] = updateFn(current)
+		return true
+	}
+
+	setState := func(updateFn func(T) T) {
+		if update(updateFn) {
+			ctx.RequestRender()
+		}
+	}
+
+	getState := func() T {
+		manager.mu.Lock()
+		defer manager.mu.Unlock()
+		if slots := manager.slots[id]; index < len(slots) {
+			if current, ok := slots[index].(T); ok {
+				return current
+			}
+		}
+		return value
+	}
+
+	return value, setState, getState
+}
+
+// useRef returns a pointer to a value stored in the component's next hook
+// slot, initialized with `initial` on the first render.
+//
+// Unlike UseState, writing through the pointer does not trigger a rerender,
+// which makes it suitable for bookkeeping done while rendering. The pointer
+// must only be used from the render of the component that owns it.
+func useRef[T any](ctx *Context, initial T) *T {
+	manager := ctx.managers.state
+	id, index := componentID(ctx.id), ctx.nextHook()
+	return stateSlot(manager, id, index, func() *T {
+		ref := new(T)
+		*ref = initial
+		return ref
+	})
+}
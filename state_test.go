@@ -0,0 +1,120 @@
+package matcha
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type intState struct{}
+
+func (c *intState) Render(ctx *Context) Component {
+	n, _ := UseState(ctx, 42)
+	return Text(strconv.Itoa(n), lipgloss.NewStyle())
+}
+
+type stringState struct{}
+
+func (c *stringState) Render(ctx *Context) Component {
+	s, _ := UseState(ctx, "text")
+	return Text(s, lipgloss.NewStyle())
+}
+
+type swap struct {
+	first *bool
+}
+
+func (c *swap) Render(ctx *Context) Component {
+	return Conditional[Component](*c.first, &intState{}, &stringState{})
+}
+
+// TestUseStateSwappedComponent mounts a component at the ID of another one
+// whose state has a different type.
+func TestUseStateSwappedComponent(t *testing.T) {
+	first := true
+	app := newTestApp(t, &swap{first: &first})
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	first = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	var got string
+	var visit func(n *node)
+	visit = func(n *node) {
+		if t, ok := n.component.(*text); ok {
+			got = t.content
+		}
+		for _, child := range n.children {
+			visit(child)
+		}
+	}
+	visit(app.scene.Load().root)
+	if got != "text" {
+		t.Errorf("rendered %q, want %q", got, "text")
+	}
+}
+
+// TestAtomUpdatePanic checks that an updater panicking leaves the atom
+// unchanged and usable.
+func TestAtomUpdatePanic(t *testing.T) {
+	atom := NewAtom(1)
+	func() {
+		defer func() { recover() }()
+		atom.update(func(int) int { panic("update") })
+	}()
+	if got := atom.Get(); got != 1 {
+		t.Fatalf("Get() = %d after a panicking update, want 1", got)
+	}
+	atom.update(func(v int) int { return v + 1 })
+	if got := atom.Get(); got != 2 {
+		t.Errorf("Get() = %d, want 2", got)
+	}
+}
+
+// TestComputedEquality checks that observers of a computed atom are only
+// notified when its derived value changes.
+func TestComputedEquality(t *testing.T) {
+	n := NewAtom(0)
+	var derived int
+	even := Computed(func() bool { derived++; return n.Get()%2 == 0 }, nil, n)
+	if derived != 0 {
+		t.Fatalf("derived %d times before being read, want 0", derived)
+	}
+	even.Get()
+
+	var notified []bool
+	even.watch(func() { notified = append(notified, even.Get()) })
+	for _, value := range []int{2, 4, 5, 7, 8} {
+		n.update(func(int) int { return value })
+	}
+	if want := []bool{false, true}; !slices.Equal(notified, want) {
+		t.Errorf("notified %v, want %v", notified, want)
+	}
+
+	// A custom equality function, across a chain of computed atoms.
+	bucket := Computed(func() int { return n.Get() / 10 }, func(a, b int) bool { return a == b }, n)
+	label := Computed(func() string { return "bucket " + strconv.Itoa(bucket.Get()) }, nil, bucket)
+	label.Get()
+	var labels []string
+	label.watch(func() { labels = append(labels, label.Get()) })
+	for _, value := range []int{9, 12, 15, 31} {
+		n.update(func(int) int { return value })
+	}
+	if want := []string{"bucket 1", "bucket 3"}; !slices.Equal(labels, want) {
+		t.Errorf("notified %v, want %v", labels, want)
+	}
+
+	// Dependencies updated together in a batch derive the value once.
+	derived = 0
+	Batch(func() {
+		n.update(func(int) int { return 40 })
+		n.update(func(int) int { return 41 })
+	})
+	if derived != 1 {
+		t.Errorf("derived %d times for a batch, want 1", derived)
+	}
+}
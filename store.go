@@ -0,0 +1,161 @@
+package matcha
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// Reducer returns the state resulting from applying an action to a state.
+// Reducers must be pure: they return a new state rather than modifying the
+// one they receive, and leave side effects to middleware.
+type Reducer[S, A any] func(state S, action A) S
+
+// Middleware wraps the dispatching of actions. It receives the store and the
+// next dispatch function in the chain, and returns a dispatch function that
+// may inspect, transform, delay or drop actions before calling next:
+//
+//	func effects(store *Store[State, Action], next func(Action)) func(Action) {
+//		return func(action Action) {
+//			next(action)
+//			if fetch, ok := action.(FetchUser); ok {
+//				go func() { store.Dispatch(UserLoaded{User: load(fetch.ID)}) }()
+//			}
+//		}
+//	}
+type Middleware[S, A any] func(store *Store[S, A], next func(action A)) func(action A)
+
+// Store holds the state of an application in the Elm/Redux style: the state
+// lives in a single atom, and is only changed by dispatching actions, which
+// a reducer applies.
+//
+// The state atom is exposed through Atom, so everything built on atoms
+// works with stores: UseAtomValue, Computed and Selector, Persist, History
+// and Batch.
+type Store[S, A any] struct {
+	atom     *Atom[S]
+	reducer  Reducer[S, A]
+	dispatch func(action A)
+}
+
+// NewStore creates a store holding `initial`. Middleware is applied in
+// order: the first one sees actions first.
+//
+//	store := NewStore(State{}, reduce, Logger[State, Action](logFile))
+func NewStore[S, A any](initial S, reducer Reducer[S, A], middleware ...Middleware[S, A]) *Store[S, A] {
+	s := &Store[S, A]{atom: NewAtom(initial), reducer: reducer}
+	s.dispatch = s.reduce
+	for i := len(middleware) - 1; i >= 0; i-- {
+		s.dispatch = middleware[i](s, s.dispatch)
+	}
+	return s
+}
+
+// reduce applies an action to the state. It is the last step of the
+// dispatch chain.
+func (s *Store[S, A]) reduce(action A) {
+	s.atom.update(func(state S) S { return s.reducer(state, action) })
+}
+
+// Dispatch sends an action through the middleware to the reducer.
+//
+// Dispatch can be called from any goroutine, including event handlers and
+// background jobs, but not from a reducer.
+func (s *Store[S, A]) Dispatch(action A) {
+	s.dispatch(action)
+}
+
+// State returns the current state.
+//
+// Thread-safe.
+func (s *Store[S, A]) State() S {
+	return s.atom.Get()
+}
+
+// Atom returns the atom holding the state. It must not be updated directly.
+func (s *Store[S, A]) Atom() *Atom[S] {
+	return s.atom
+}
+
+// Logger returns a middleware writing every action and the state it results
+// in to `w`.
+func Logger[S, A any](w io.Writer) Middleware[S, A] {
+	return func(store *Store[S, A], next func(action A)) func(action A) {
+		return func(action A) {
+			next(action)
+			fmt.Fprintf(w, "action %T %+v -> %+v\n", action, action, store.State())
+		}
+	}
+}
+
+// selection is the bookkeeping of UseSelector: the value selected by the
+// last render, read by the subscriber from the goroutine updating the store.
+type selection[T any] struct {
+	value   atomic.Pointer[T]
+	stopped atomic.Bool // Set once the component is unmounted.
+	mounted bool
+}
+
+// UseSelector returns the part of the store's state picked by `selector`,
+// and rerenders the component only when that part changes, as reported by
+// `equal` (== for comparable types by default, as in NewAtom). Updates to
+// the rest of the state leave the component alone:
+//
+//	count := UseSelector(ctx, store, func(s State) int { return len(s.Todos) })
+//
+// The subscription ends when the component is unmounted.
+//
+// Like all slot-based hooks, UseSelector must be called unconditionally and
+// in the same order on every render.
+func UseSelector[S, A, T any](ctx *Context, store *Store[S, A], selector func(S) T, equal ...func(a, b T) bool) T {
+	atom := store.atom
+	same := equalFunc(equal)
+	ref := useRef[*selection[T]](ctx, nil)
+	if *ref == nil {
+		*ref = new(selection[T])
+	}
+	sel := *ref
+	prefix := fmt.Sprintf("%s/%p/", ctx.id, sel)
+
+	if !sel.mounted {
+		sel.mounted = true
+		sel.stopped.Store(false)
+		ctx.managers.lifecycle.onUnmount(componentID(ctx.id), func() {
+			// Subscribe again if the component is mounted again.
+			sel.mounted = false
+			sel.stopped.Store(true)
+			atom.mu.Lock()
+			for id := range atom.subscribers {
+				if strings.HasPrefix(id, prefix) {
+					delete(atom.subscribers, id)
+				}
+			}
+			atom.mu.Unlock()
+		})
+	}
+
+	var subscribe func()
+	subscribe = func() {
+		atom.mu.RLock()
+		id := fmt.Sprintf("%s%d", prefix, atom.version)
+		atom.mu.RUnlock()
+		atom.subscribe(&Subscriber[S]{id: id, cb: func(state S) {
+			if sel.stopped.Load() {
+				return
+			}
+			if last := sel.value.Load(); last != nil && same != nil && same(*last, selector(state)) {
+				// Subscriptions only fire once: keep listening for a change
+				// of the selected part.
+				subscribe()
+				return
+			}
+			ctx.RequestRender()
+		}})
+	}
+	subscribe()
+
+	value := selector(atom.value())
+	sel.value.Store(&value)
+	return value
+}
@@ -0,0 +1,43 @@
+package matcha
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+type selecting struct {
+	store *Store[[2]int, int]
+}
+
+func (c *selecting) Render(ctx *Context) Component {
+	UseSelector(ctx, c.store, func(s [2]int) int { return s[0] })
+	return Text("selecting", lipgloss.NewStyle())
+}
+
+// TestUseSelectorUnmount checks that a selector stops listening to the store
+// once its component is unmounted, including when updates leave the
+// selected part unchanged.
+func TestUseSelectorUnmount(t *testing.T) {
+	store := NewStore([2]int{}, func(s [2]int, i int) [2]int { s[i]++; return s })
+	show := true
+	app := newTestApp(t, &shown{show: &show, child: &selecting{store: store}})
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	store.Dispatch(1)
+	if got := len(store.atom.subscribers); got != 1 {
+		t.Fatalf("%d subscribers after an unrelated update, want 1", got)
+	}
+
+	show = false
+	app.scheduler.invalidate()
+	drawFrame(app)
+	if got := len(store.atom.subscribers); got != 0 {
+		t.Errorf("%d subscribers after unmount, want 0", got)
+	}
+	store.Dispatch(1)
+	if got := len(store.atom.subscribers); got != 0 {
+		t.Errorf("%d subscribers after an update following unmount, want 0", got)
+	}
+}
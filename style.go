@@ -186,12 +186,32 @@ func isBorder(row, column, height, width int, style lipgloss.Style) (bool, int)
 }
 
 // lipglossColorToTcell converts a Lip Gloss TerminalColor into a tcell.Color.
-// If the alpha channel is zero, it returns ColorDefault (meaning "no color").
-// Otherwise, it constructs a 24-bit RGB tcell color.
+// If the color is unset (NoColor) or the alpha channel is zero, it returns
+// ColorDefault (meaning "no color"). Otherwise, it constructs a 24-bit RGB
+// tcell color.
 func lipglossColorToTcell(color lipgloss.TerminalColor) tcell.Color {
+	if _, ok := color.(lipgloss.NoColor); ok || color == nil {
+		return tcell.ColorDefault
+	}
 	r, g, b, a := color.RGBA()
 	if a == 0 {
 		return tcell.ColorDefault
 	}
 	return tcell.NewRGBColor(int32(r/257), int32(g/257), int32(b/257))
 }
+
+// inlineStyle keeps only the text attributes and colors of a Lip Gloss
+// style, dropping its frame (size, alignment, margins, padding and borders).
+// It is used for text drawn inside a container that already applies the
+// frame of the same style.
+func inlineStyle(style lipgloss.Style) lipgloss.Style {
+	return lipgloss.NewStyle().
+		Bold(style.GetBold()).
+		Faint(style.GetFaint()).
+		Italic(style.GetItalic()).
+		Reverse(style.GetReverse()).
+		Strikethrough(style.GetStrikethrough()).
+		Underline(style.GetUnderline()).
+		Background(style.GetBackground()).
+		Foreground(style.GetForeground())
+}
@@ -0,0 +1,242 @@
+package matcha
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Tab is a single entry of a Tabs component.
+type Tab struct {
+	// Key identifies the tab. The panel is rendered under this key, so its
+	// local state is kept while other tabs are active and survives tabs being
+	// reordered or other tabs being closed.
+	Key      string
+	Title    string
+	Content  Component
+	Closable bool
+}
+
+// TabsProps configures a Tabs component.
+type TabsProps struct {
+	// ID is the focusable ID registered through UseFocus. Clicking a tab
+	// focuses the component.
+	ID   string
+	Tabs []Tab
+	// Active is the key of the initially active tab. Defaults to the first one.
+	Active string
+	// OnChange is called with the key of the newly activated tab.
+	OnChange func(key string)
+	// OnClose is called with the key of a closable tab that the user closed.
+	// The tab is only removed once the caller drops it from Tabs.
+	OnClose func(key string)
+	// OnReorder is called with the tab keys in their new order when the user
+	// moves the active tab. The order only changes once the caller applies it.
+	OnReorder func(keys []string)
+
+	BarStyle       lipgloss.Style
+	TabStyle       lipgloss.Style
+	ActiveTabStyle lipgloss.Style
+	PanelStyle     lipgloss.Style
+}
+
+type tabs struct {
+	props TabsProps
+}
+
+// Tabs renders a tab bar and the panel of the active tab.
+//
+// Tabs are switched by clicking their title, with Ctrl+Tab/Ctrl+Shift+Tab or
+// Ctrl+PgDn/Ctrl+PgUp, and with the number keys 1-9. Alt+Left/Alt+Right move
+// the active tab and Ctrl+W closes it if it is closable.
+//
+// Only the active panel is rendered. The local state of inactive panels is
+// kept until they are shown again, and is dropped when their tab is removed
+// or the Tabs component is unmounted.
+func Tabs(props TabsProps) Component {
+	return &tabs{props: props}
+}
+
+func (t *tabs) Render(ctx *Context) Component {
+	_, setFocus, _ := UseFocus(ctx, t.props.ID)
+	activeKey, setActiveKey := UseState(ctx, t.props.Active)
+
+	active := slices.IndexFunc(t.props.Tabs, func(tab Tab) bool { return tab.Key == activeKey })
+	if active < 0 {
+		active = 0
+	}
+
+	activate := func(index int) {
+		if index < 0 || index >= len(t.props.Tabs) {
+			return
+		}
+		key := t.props.Tabs[index].Key
+		setActiveKey(func(string) string { return key })
+		if t.props.OnChange != nil {
+			t.props.OnChange(key)
+		}
+	}
+
+	closeTab := func(index int) {
+		if index < 0 || index >= len(t.props.Tabs) || !t.props.Tabs[index].Closable {
+			return
+		}
+		if index == active {
+			// Move to a neighbour before the caller drops the tab.
+			if index+1 < len(t.props.Tabs) {
+				activate(index + 1)
+			} else {
+				activate(index - 1)
+			}
+		}
+		if t.props.OnClose != nil {
+			t.props.OnClose(t.props.Tabs[index].Key)
+		}
+	}
+
+	move := func(offset int) {
+		target := active + offset
+		if t.props.OnReorder == nil || target < 0 || target >= len(t.props.Tabs) {
+			return
+		}
+		keys := make([]string, len(t.props.Tabs))
+		for i, tab := range t.props.Tabs {
+			keys[i] = tab.Key
+		}
+		keys[active], keys[target] = keys[target], keys[active]
+		t.props.OnReorder(keys)
+	}
+
+	panels := make([]componentID, 0, len(t.props.Tabs))
+	for _, tab := range t.props.Tabs {
+		panels = append(panels, panelID(ctx.id, tab.Key))
+	}
+	ctx.managers.lifecycle.retain(componentID(ctx.id), panels...)
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok || len(t.props.Tabs) == 0 {
+			return false
+		}
+		ctrl := key.Modifiers()&tcell.ModCtrl != 0
+		alt := key.Modifiers()&tcell.ModAlt != 0
+		count := len(t.props.Tabs)
+
+		switch {
+		case ctrl && key.Key() == tcell.KeyTab, ctrl && key.Key() == tcell.KeyPgDn:
+			activate((active + 1) % count)
+		case ctrl && key.Key() == tcell.KeyBacktab, ctrl && key.Key() == tcell.KeyPgUp:
+			activate((active - 1 + count) % count)
+		case alt && key.Key() == tcell.KeyRight:
+			move(1)
+		case alt && key.Key() == tcell.KeyLeft:
+			move(-1)
+		case key.Key() == tcell.KeyCtrlW:
+			closeTab(active)
+		case key.Key() == tcell.KeyRune && key.Rune() >= '1' && key.Rune() <= '9':
+			activate(int(key.Rune() - '1'))
+		default:
+			return false
+		}
+		return true
+	})
+
+	titles := make([]Component, 0, len(t.props.Tabs))
+	for i, tab := range t.props.Tabs {
+		style := t.props.TabStyle
+		if i == active {
+			style = t.props.ActiveTabStyle
+		}
+		titles = append(titles, &tabTitle{
+			tab:   tab,
+			style: style,
+			onActivate: func() {
+				setFocus(t.props.ID)
+				activate(i)
+			},
+			onClose: func() { closeTab(i) },
+		})
+	}
+
+	children := []Component{Row(titles, t.props.BarStyle)}
+	if active < len(t.props.Tabs) {
+		tab := t.props.Tabs[active]
+		children = append(children, &tabPanel{key: tab.Key, content: tab.Content, style: t.props.PanelStyle})
+	}
+
+	return Column(children, lipgloss.NewStyle())
+}
+
+// tabTitle is a clickable title in the tab bar.
+type tabTitle struct {
+	tab        Tab
+	style      lipgloss.Style
+	onActivate func()
+	onClose    func()
+}
+
+func (t *tabTitle) Key() string {
+	return t.tab.Key
+}
+
+func (t *tabTitle) Render(ctx *Context) Component {
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if !isPrimaryClick(event) {
+			return false
+		}
+		t.onActivate()
+		return true
+	})
+
+	inline := inlineStyle(t.style)
+	children := []Component{Text(t.tab.Title, inline)}
+	if t.tab.Closable {
+		children = append(children, &tabCloseButton{style: inline, onClose: t.onClose})
+	}
+	return Row(children, t.style)
+}
+
+// tabCloseButton is the close glyph of a closable tab. Clicks on it are
+// handled here, so they never reach the title and activate the tab.
+type tabCloseButton struct {
+	style   lipgloss.Style
+	onClose func()
+}
+
+func (t *tabCloseButton) Render(ctx *Context) Component {
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if !isPrimaryClick(event) {
+			return false
+		}
+		t.onClose()
+		return true
+	})
+	return Text(" ×", t.style)
+}
+
+// tabPanel renders a tab's content under the tab's key, so that each panel
+// keeps its own component IDs (and therefore its local state) regardless of
+// which tab is active.
+type tabPanel struct {
+	key     string
+	content Component
+	style   lipgloss.Style
+}
+
+// panelID returns the ID the panel of the tab with the given key has when
+// it is active, below the Column rendered by the Tabs component.
+func panelID(tabsID, key string) componentID {
+	return componentID(childID(childID(tabsID, 0, nil), 1, &tabPanel{key: key}))
+}
+
+func (t *tabPanel) Key() string {
+	return t.key
+}
+
+func (t *tabPanel) Render(ctx *Context) Component {
+	if t.content == nil {
+		return Column(nil, t.style)
+	}
+	return Column([]Component{t.content}, t.style)
+}
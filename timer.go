@@ -0,0 +1,112 @@
+package matcha
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// timerState is the local state of UseInterval and UseTimeout. Apart from
+// the callback, it is only accessed on the build loop.
+type timerState struct {
+	callback atomic.Pointer[func()] // Callback of the last render.
+	delay    time.Duration
+	cancel   context.CancelFunc // Stops the running timer.
+	started  bool               // Whether the timer was started for the current delay.
+	mounted  bool               // Whether the unmount cleanup is registered.
+}
+
+// stop stops the running timer, if any.
+func (s *timerState) stop() {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
+
+// UseInterval calls `fn` every `d` for as long as the component is mounted:
+//
+//	now, setNow := UseState(ctx, time.Now())
+//	UseInterval(ctx, time.Second, func() { setNow(func(time.Time) time.Time { return time.Now() }) })
+//
+// The interval starts when the component is first rendered and stops when it
+// is unmounted or the application quits. Changing `d` restarts it, and a
+// non-positive `d` pauses it. Callbacks run on the event dispatcher, one at
+// a time and never concurrently with event handlers, and always call the
+// `fn` of the latest render. Like event handlers, callbacks update state to
+// cause a rerender.
+//
+// Like all slot-based hooks, UseInterval must be called unconditionally and
+// in the same order on every render.
+func UseInterval(ctx *Context, d time.Duration, fn func()) {
+	useTimer(ctx, d, fn, true)
+}
+
+// UseTimeout calls `fn` once, `d` after the component is first rendered,
+// unless it is unmounted first. Changing `d` before the timeout fires starts
+// it over, and a non-positive `d` cancels it. Callbacks are delivered as for
+// UseInterval.
+//
+// Like all slot-based hooks, UseTimeout must be called unconditionally and
+// in the same order on every render.
+func UseTimeout(ctx *Context, d time.Duration, fn func()) {
+	useTimer(ctx, d, fn, false)
+}
+
+// useTimer implements UseInterval and UseTimeout.
+func useTimer(ctx *Context, d time.Duration, fn func(), repeat bool) {
+	state := useRef(ctx, &timerState{})
+	s := *state
+	s.callback.Store(&fn)
+	lifecycle := ctx.managers.lifecycle
+
+	if !s.mounted {
+		s.mounted = true
+		lifecycle.onUnmount(componentID(ctx.id), func() {
+			s.stop()
+			// Start over if the component is mounted again.
+			s.mounted, s.started = false, false
+		})
+	}
+	if s.started && s.delay == d {
+		return
+	}
+	s.stop()
+	s.started, s.delay = true, d
+	if d <= 0 {
+		return
+	}
+
+	timerCtx, cancel := lifecycle.context()
+	s.cancel = cancel
+	calls := ctx.channels.call
+	go func() {
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-timerCtx.Done():
+				return
+			}
+			call := func() {
+				// The timer may have been stopped while the call was waiting.
+				if timerCtx.Err() != nil {
+					return
+				}
+				if !repeat {
+					cancel()
+				}
+				(*s.callback.Load())()
+			}
+			select {
+			case calls <- call:
+			case <-timerCtx.Done():
+				return
+			}
+			if !repeat {
+				return
+			}
+		}
+	}()
+}
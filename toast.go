@@ -0,0 +1,252 @@
+package matcha
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// Severity classifies a Toast and picks its color.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeveritySuccess
+	SeverityWarning
+	SeverityError
+)
+
+// color returns the accent color used to draw toasts of this severity.
+func (s Severity) color() lipgloss.Color {
+	switch s {
+	case SeveritySuccess:
+		return lipgloss.Color("#04B575")
+	case SeverityWarning:
+		return lipgloss.Color("#E5C07B")
+	case SeverityError:
+		return lipgloss.Color("#E06C75")
+	default:
+		return lipgloss.Color("#5DA9E9")
+	}
+}
+
+// defaultToastTimeout is used for toasts that do not set a Timeout.
+const defaultToastTimeout = 5 * time.Second
+
+// maxVisibleToasts is the number of toasts shown at once. Further toasts are
+// queued until earlier ones are dismissed.
+const maxVisibleToasts = 4
+
+// ToastAction is a clickable action shown on a toast. Pressing it dismisses
+// the toast.
+type ToastAction struct {
+	Label   string
+	OnPress func()
+}
+
+// Toast is a transient notification.
+type Toast struct {
+	Message  string
+	Severity Severity
+	// Timeout is how long the toast stays on screen once it is shown.
+	// Zero uses a default of five seconds and a negative value keeps the
+	// toast until it is dismissed.
+	Timeout time.Duration
+	Actions []ToastAction
+}
+
+// toastEntry is a queued or visible toast.
+type toastEntry struct {
+	id    string
+	toast Toast
+	timer *time.Timer // Started once the toast becomes visible.
+}
+
+// toastManager keeps the queue of toasts and the overlay they are drawn in.
+//
+// The overlay is opened with the first toast and removed with the last one,
+// so an idle application has no toast overlay at all.
+//
+// All access is synchronized with a mutex for concurrent safety.
+type toastManager struct {
+	entries []*toastEntry
+	next    int
+	overlay string // ID of the overlay showing the toasts, empty when closed.
+	mu      sync.Mutex
+}
+
+// newToastManager creates and returns a new, empty toastManager.
+func newToastManager() *toastManager {
+	return &toastManager{}
+}
+
+// add queues a toast and returns its ID.
+//
+// Thread-safe.
+func (t *toastManager) add(ctx *Context, toast Toast) string {
+	t.mu.Lock()
+	t.next++
+	id := fmt.Sprintf("toast-%d", t.next)
+	t.entries = append(t.entries, &toastEntry{id: id, toast: toast})
+	if t.overlay == "" {
+		t.overlay = ctx.managers.overlay.push(&toastStack{}, overlayOptions{place: placeBottomRight})
+	}
+	t.startTimers(ctx)
+	overlay := t.overlay
+	t.mu.Unlock()
+
+	ctx.scheduler.invalidate(componentID(overlay))
+	return id
+}
+
+// dismiss removes a toast. It is a no-op if the toast is already gone.
+//
+// Thread-safe.
+func (t *toastManager) dismiss(ctx *Context, id string) {
+	t.mu.Lock()
+	index := slices.IndexFunc(t.entries, func(entry *toastEntry) bool { return entry.id == id })
+	if index < 0 {
+		t.mu.Unlock()
+		return
+	}
+	if timer := t.entries[index].timer; timer != nil {
+		timer.Stop()
+	}
+	t.entries = slices.Delete(t.entries, index, index+1)
+	if len(t.entries) == 0 {
+		ctx.managers.overlay.remove(t.overlay)
+		t.overlay = ""
+	}
+	t.startTimers(ctx)
+	overlay := t.overlay
+	t.mu.Unlock()
+
+	if overlay != "" {
+		ctx.scheduler.invalidate(componentID(overlay))
+	} else {
+		ctx.RequestRender()
+	}
+}
+
+// startTimers starts the expiry timers of toasts that just became visible.
+// Must be called with the lock held.
+func (t *toastManager) startTimers(ctx *Context) {
+	for _, entry := range t.entries[:min(len(t.entries), maxVisibleToasts)] {
+		if entry.timer != nil || entry.toast.Timeout < 0 {
+			continue
+		}
+		timeout := entry.toast.Timeout
+		if timeout == 0 {
+			timeout = defaultToastTimeout
+		}
+		id := entry.id
+		entry.timer = time.AfterFunc(timeout, func() { t.dismiss(ctx, id) })
+	}
+}
+
+// visible returns the toasts currently on screen, oldest first.
+//
+// Thread-safe.
+func (t *toastManager) visible() []toastEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	visible := make([]toastEntry, 0, maxVisibleToasts)
+	for _, entry := range t.entries[:min(len(t.entries), maxVisibleToasts)] {
+		visible = append(visible, *entry)
+	}
+	return visible
+}
+
+// Notify shows a toast in the bottom-right corner of the screen and returns
+// a function that dismisses it early.
+//
+// Toasts are stacked, oldest on top. At most four are shown at once; the
+// rest wait in a queue, and their timeout only starts once they are shown.
+// A toast is also dismissed by clicking it or one of its actions.
+//
+// Notify can be called from any goroutine, including event handlers and
+// background jobs.
+func Notify(ctx *Context, toast Toast) (dismiss func()) {
+	manager := ctx.managers.toast
+	id := manager.add(ctx, toast)
+	return func() { manager.dismiss(ctx, id) }
+}
+
+// toastStack is the overlay component drawing the visible toasts.
+type toastStack struct{}
+
+func (t *toastStack) Render(ctx *Context) Component {
+	entries := ctx.managers.toast.visible()
+	children := make([]Component, 0, len(entries))
+	for _, entry := range entries {
+		children = append(children, &toastView{id: entry.id, toast: entry.toast})
+	}
+	return Column(children, lipgloss.NewStyle())
+}
+
+// toastView draws a single toast.
+type toastView struct {
+	id    string
+	toast Toast
+}
+
+func (t *toastView) Key() string {
+	return t.id
+}
+
+func (t *toastView) Render(ctx *Context) Component {
+	manager := ctx.managers.toast
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if !isPrimaryClick(event) {
+			return false
+		}
+		manager.dismiss(ctx, t.id)
+		return true
+	})
+
+	accent := t.toast.Severity.color()
+	children := []Component{Text(t.toast.Message, lipgloss.NewStyle().MaxWidth(48))}
+	if len(t.toast.Actions) > 0 {
+		actions := make([]Component, 0, len(t.toast.Actions))
+		for _, action := range t.toast.Actions {
+			actions = append(actions, &toastActionView{
+				action: action,
+				style:  lipgloss.NewStyle().Foreground(accent).Bold(true).MarginRight(2),
+				dismiss: func() {
+					manager.dismiss(ctx, t.id)
+				},
+			})
+		}
+		children = append(children, Row(actions, lipgloss.NewStyle()))
+	}
+
+	return Column(children, lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(accent).
+		Padding(0, 1))
+}
+
+// toastActionView is a clickable action of a toast.
+type toastActionView struct {
+	action  ToastAction
+	style   lipgloss.Style
+	dismiss func()
+}
+
+func (t *toastActionView) Render(ctx *Context) Component {
+	UseEvent(ctx, func(event tcell.Event) bool {
+		if !isPrimaryClick(event) {
+			return false
+		}
+		t.dismiss()
+		if t.action.OnPress != nil {
+			t.action.OnPress()
+		}
+		return true
+	})
+	return Text(t.action.Label, t.style)
+}
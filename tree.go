@@ -0,0 +1,276 @@
+package matcha
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gdamore/tcell/v2"
+)
+
+// TreeNode is a single entry of a TreeView.
+//
+// Nodes are identified by the path of keys leading to them from the top
+// level, so expansion and cursor state survive the data being rebuilt as long
+// as the keys stay the same.
+type TreeNode struct {
+	Key      string
+	Label    string
+	Children []TreeNode
+	// Lazy marks a node whose children are not known up front. They are
+	// requested through TreeViewProps.Load the first time the node is expanded.
+	Lazy bool
+}
+
+// expandable reports whether the node can be expanded.
+func (n TreeNode) expandable() bool {
+	return len(n.Children) > 0 || n.Lazy
+}
+
+// TreeViewProps configures a TreeView.
+type TreeViewProps struct {
+	// ID is the focusable ID registered through UseFocus. The tree only
+	// reacts to the keyboard while it is focused.
+	ID    string
+	Nodes []TreeNode
+	// Load returns the children of a lazy node. It is called from the event
+	// dispatch goroutine the first time the node is expanded, and its result
+	// is cached for as long as the tree is mounted.
+	Load func(path []string, node TreeNode) []TreeNode
+	// OnSelect is called when Enter is pressed on a node.
+	OnSelect    func(path []string, node TreeNode)
+	Style       lipgloss.Style
+	CursorStyle lipgloss.Style
+}
+
+// treePathSeparator joins node keys into the path strings used as state keys.
+// A control character is used so that keys may contain any printable text.
+const treePathSeparator = "\x1f"
+
+// treeState is the local state of a TreeView. It is replaced, never mutated,
+// on every update.
+type treeState struct {
+	cursor   string
+	expanded map[string]bool
+	loaded   map[string][]TreeNode
+}
+
+// treeRow is a visible line of the tree.
+type treeRow struct {
+	path   string
+	node   TreeNode
+	parent string
+	guide  string
+}
+
+type treeView struct {
+	props TreeViewProps
+}
+
+// TreeView renders hierarchical data with expandable nodes and guide lines.
+//
+// Keyboard (while focused):
+//   - Up/Down move the cursor, Home/End jump to the first/last row.
+//   - Right expands the node under the cursor, or moves to its first child
+//     if it is already expanded.
+//   - Left collapses the node under the cursor, or moves to its parent if it
+//     is already collapsed.
+//   - Enter selects the node under the cursor.
+func TreeView(props TreeViewProps) Component {
+	return &treeView{props: props}
+}
+
+func (t *treeView) Render(ctx *Context) Component {
+	isFocused, _, _ := UseFocus(ctx, t.props.ID)
+	state, setState, getState := useState(ctx, treeState{
+		expanded: map[string]bool{},
+		loaded:   map[string][]TreeNode{},
+	})
+
+	rows, cursor := t.rows(state)
+
+	UseEvent(ctx, func(event tcell.Event) bool {
+		key, ok := event.(*tcell.EventKey)
+		if !ok || len(rows) == 0 {
+			return false
+		}
+
+		// Several keys may be handled before the next frame, so each one
+		// works from the latest state rather than the rendered one.
+		switch key.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyHome, tcell.KeyEnd, tcell.KeyLeft:
+			setState(func(s treeState) treeState {
+				return t.navigate(s, key.Key())
+			})
+		case tcell.KeyRight:
+			latest := getState()
+			rows, cursor := t.rows(latest)
+			current := rows[cursor]
+			if !current.node.expandable() {
+				return true
+			}
+			if latest.expanded[current.path] {
+				setState(func(s treeState) treeState {
+					return t.navigate(s, key.Key())
+				})
+				return true
+			}
+			// Loading calls back into the application, so it is done
+			// outside of the update.
+			children := t.load(latest, current)
+			setState(func(s treeState) treeState {
+				return s.withExpanded(current.path, true, children)
+			})
+		case tcell.KeyEnter:
+			rows, cursor := t.rows(getState())
+			if t.props.OnSelect != nil {
+				current := rows[cursor]
+				t.props.OnSelect(strings.Split(current.path, treePathSeparator), current.node)
+			}
+		default:
+			return false
+		}
+		return true
+	})
+
+	children := make([]Component, 0, len(rows))
+	for i, row := range rows {
+		style := t.props.Style
+		if isFocused && i == cursor {
+			style = t.props.CursorStyle
+		}
+		children = append(children, Text(row.guide+treeMarker(state, row)+row.node.Label, style, row.path))
+	}
+
+	return Column(children, lipgloss.NewStyle())
+}
+
+// rows returns the visible rows for the state and the index of the row
+// under the cursor.
+func (t *treeView) rows(state treeState) ([]treeRow, int) {
+	rows := t.flatten(state, t.props.Nodes, "", "")
+	for i, row := range rows {
+		if row.path == state.cursor {
+			return rows, i
+		}
+	}
+	return rows, 0
+}
+
+// navigate returns the state after moving the cursor with `key`, or after
+// collapsing the node under it with Left. Expanding, which may load
+// children, is left to the caller.
+func (t *treeView) navigate(s treeState, key tcell.Key) treeState {
+	rows, cursor := t.rows(s)
+	if len(rows) == 0 {
+		return s
+	}
+	current := rows[cursor]
+
+	switch key {
+	case tcell.KeyUp:
+		s.cursor = rows[max(cursor-1, 0)].path
+	case tcell.KeyDown:
+		s.cursor = rows[min(cursor+1, len(rows)-1)].path
+	case tcell.KeyHome:
+		s.cursor = rows[0].path
+	case tcell.KeyEnd:
+		s.cursor = rows[len(rows)-1].path
+	case tcell.KeyRight:
+		if cursor+1 < len(rows) && rows[cursor+1].parent == current.path {
+			s.cursor = rows[cursor+1].path
+		}
+	case tcell.KeyLeft:
+		if current.node.expandable() && s.expanded[current.path] {
+			return s.withExpanded(current.path, false, nil)
+		}
+		if current.parent != "" {
+			s.cursor = current.parent
+		}
+	}
+	return s
+}
+
+// flatten returns the rows that are currently visible, in display order.
+func (t *treeView) flatten(state treeState, nodes []TreeNode, parent, guide string) []treeRow {
+	var rows []treeRow
+	for i, node := range nodes {
+		path := node.Key
+		if parent != "" {
+			path = parent + treePathSeparator + node.Key
+		}
+
+		last := i == len(nodes)-1
+		branch, indent := "├─ ", "│  "
+		if last {
+			branch, indent = "└─ ", "   "
+		}
+
+		rows = append(rows, treeRow{path: path, node: node, parent: parent, guide: guide + branch})
+		if state.expanded[path] {
+			rows = append(rows, t.flatten(state, state.children(path, node), path, guide+indent)...)
+		}
+	}
+	return rows
+}
+
+// load returns the children of the row's node, calling props.Load for lazy
+// nodes that have not been loaded yet.
+func (t *treeView) load(state treeState, row treeRow) []TreeNode {
+	if !row.node.Lazy || row.node.Children != nil {
+		return nil
+	}
+	if children, ok := state.loaded[row.path]; ok {
+		return children
+	}
+	if t.props.Load == nil {
+		return []TreeNode{}
+	}
+	children := t.props.Load(strings.Split(row.path, treePathSeparator), row.node)
+	if children == nil {
+		children = []TreeNode{}
+	}
+	return children
+}
+
+// children returns the node's own children, or the cached result of loading
+// them for lazy nodes.
+func (s treeState) children(path string, node TreeNode) []TreeNode {
+	if node.Lazy && node.Children == nil {
+		return s.loaded[path]
+	}
+	return node.Children
+}
+
+// withExpanded returns a copy of the state with the node at `path` expanded
+// or collapsed. Lazily loaded children, if any, are cached alongside.
+func (s treeState) withExpanded(path string, expanded bool, loaded []TreeNode) treeState {
+	next := treeState{
+		cursor:   s.cursor,
+		expanded: make(map[string]bool, len(s.expanded)+1),
+		loaded:   make(map[string][]TreeNode, len(s.loaded)+1),
+	}
+	maps.Copy(next.expanded, s.expanded)
+	maps.Copy(next.loaded, s.loaded)
+	if expanded {
+		next.expanded[path] = true
+	} else {
+		delete(next.expanded, path)
+	}
+	if loaded != nil {
+		next.loaded[path] = loaded
+	}
+	return next
+}
+
+// treeMarker returns the expand/collapse indicator drawn before a label.
+func treeMarker(state treeState, row treeRow) string {
+	switch {
+	case !row.node.expandable():
+		return "  "
+	case state.expanded[row.path]:
+		return "▾ "
+	default:
+		return "▸ "
+	}
+}
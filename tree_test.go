@@ -0,0 +1,47 @@
+package matcha
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// press delivers a key to the handler of the component with the given ID,
+// as the dispatcher would, without drawing a frame.
+func press(t *testing.T, app *App, id string, key tcell.Key, ch rune) {
+	t.Helper()
+	app.managers.event.mu.Lock()
+	handler, ok := app.managers.event.handlers[id]
+	app.managers.event.mu.Unlock()
+	if !ok {
+		t.Fatalf("no handler for %q", id)
+	}
+	handler(tcell.NewEventKey(key, ch, tcell.ModNone))
+}
+
+// TestTreeViewKeysBeforeFrame handles several keys before the tree is
+// rendered again; each must start from where the previous one left.
+func TestTreeViewKeysBeforeFrame(t *testing.T) {
+	var selected []string
+	app := newTestApp(t, TreeView(TreeViewProps{
+		ID: "tree",
+		Nodes: []TreeNode{
+			{Key: "a", Label: "a"},
+			{Key: "b", Label: "b", Children: []TreeNode{{Key: "c", Label: "c"}}},
+			{Key: "d", Label: "d"},
+		},
+		OnSelect: func(path []string, _ TreeNode) { selected = path },
+	}))
+	app.scheduler.invalidate()
+	drawFrame(app)
+
+	press(t, app, "root", tcell.KeyDown, 0)
+	press(t, app, "root", tcell.KeyRight, 0)
+	press(t, app, "root", tcell.KeyRight, 0)
+	press(t, app, "root", tcell.KeyDown, 0)
+	press(t, app, "root", tcell.KeyEnter, 0)
+	if want := []string{"d"}; !slices.Equal(selected, want) {
+		t.Errorf("selected %v, want %v", selected, want)
+	}
+}